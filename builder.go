@@ -0,0 +1,209 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Builder constructs a Query in code:
+//
+//	q := query.New().
+//		Where("name").Substr("x").
+//		Where("age").Between(18, 65).
+//		OrderBy("-created_at").
+//		Page(2, 50).
+//		Query()
+type Builder struct {
+	q Query
+}
+
+func New() *Builder {
+	return &Builder{
+		q: Query{
+			Search:     "",
+			Filter:     Filter{},
+			Sort:       Sort{},
+			Pagination: Pagination{Offset: 0, Limit: 0, After: nil, Clamp: PaginationClamp{}},
+			Select:     nil,
+		},
+	}
+}
+
+// Query returns a copy of the built query.
+func (b *Builder) Query() Query {
+	return b.q.Copy()
+}
+
+// Filter returns a copy of the built filter, used to nest builders into groups.
+func (b *Builder) Filter() Filter {
+	return b.q.Copy().Filter
+}
+
+// Build returns the built query validated against the model.
+func Build[Model any](b *Builder, opts ...Option) (Query, error) {
+	q := b.Query()
+	return q, Validate[Model](q, opts...)
+}
+
+// Where starts a condition on the field.
+func (b *Builder) Where(field string) *Condition {
+	return &Condition{b: b, field: field}
+}
+
+// With adds filters as they are, e.g. built from typed Field descriptors.
+func (b *Builder) With(fs ...FieldFilter) *Builder {
+	b.q.Filter = append(b.q.Filter, fs...)
+	return b
+}
+
+// Or adds a group matching when any of the builders filters match.
+func (b *Builder) Or(bs ...*Builder) *Builder {
+	return b.add(Or(builderFilters(bs)...))
+}
+
+// And adds a group matching when all of the builders filters match.
+func (b *Builder) And(bs ...*Builder) *Builder {
+	return b.add(And(builderFilters(bs)...))
+}
+
+// Not adds a group matching when none of the builders filters match.
+func (b *Builder) Not(bs ...*Builder) *Builder {
+	return b.add(Not(builderFilters(bs)...))
+}
+
+// ElemMatch adds a condition matching when a single element of the slice field
+// matches all of the builder filters, paths in elem are relative to the element.
+func (b *Builder) ElemMatch(field string, elem *Builder) *Builder {
+	return b.add(ElemMatch(field, elem.Filter()))
+}
+
+func builderFilters(bs []*Builder) []Filter {
+	out := make([]Filter, 0, len(bs))
+	for _, b := range bs {
+		out = append(out, b.Filter())
+	}
+	return out
+}
+
+func (b *Builder) add(f FieldFilter) *Builder {
+	b.q.Filter = append(b.q.Filter, f)
+	return b
+}
+
+// OrderBy adds sort keys in the syntax of the sort parameter: "-created_at", "name:nullslast".
+func (b *Builder) OrderBy(keys ...string) *Builder {
+	for _, k := range keys {
+		for _, key := range strings.Split(k, ",") {
+			key = strings.TrimSpace(key)
+			if key != "" {
+				b.q.Sort.SetField(parseSortKey(key))
+			}
+		}
+	}
+	return b
+}
+
+func (b *Builder) Search(s string) *Builder {
+	b.q.Search = s
+	return b
+}
+
+func (b *Builder) Select(fields ...string) *Builder {
+	b.q.Select = SliceUnique(append(b.q.Select, fields...))
+	return b
+}
+
+func (b *Builder) Offset(offset uint64) *Builder {
+	b.q.Pagination.Offset = offset
+	return b
+}
+
+func (b *Builder) Limit(limit uint64) *Builder {
+	b.q.Pagination.Limit = limit
+	return b
+}
+
+// Page sets offset and limit for the 1-based page number.
+func (b *Builder) Page(page, size uint64) *Builder {
+	if page < 1 {
+		page = 1
+	}
+	b.q.Pagination.Offset = (page - 1) * size
+	b.q.Pagination.Limit = size
+	return b
+}
+
+func (b *Builder) After(c Cursor) *Builder {
+	b.q.Pagination.After = c
+	return b
+}
+
+// Condition is a pending filter on a field started with Builder.Where.
+type Condition struct {
+	b     *Builder
+	field string
+}
+
+func (c *Condition) Op(op Operator, value any) *Builder {
+	return c.b.add(FieldFilter{Field: c.field, Op: op, Value: value})
+}
+
+func (c *Condition) Eq(v any) *Builder     { return c.Op(OperatorEqual, v) }
+func (c *Condition) Ne(v any) *Builder     { return c.Op(OperatorNotEqual, v) }
+func (c *Condition) Gt(v any) *Builder     { return c.Op(OperatorGreater, v) }
+func (c *Condition) Gte(v any) *Builder    { return c.Op(OperatorGreaterOrEqual, v) }
+func (c *Condition) Lt(v any) *Builder     { return c.Op(OperatorLess, v) }
+func (c *Condition) Lte(v any) *Builder    { return c.Op(OperatorLessOrEqual, v) }
+func (c *Condition) Substr(v any) *Builder { return c.Op(OperatorSubString, v) }
+func (c *Condition) Prefix(v any) *Builder { return c.Op(OperatorPrefix, v) }
+func (c *Condition) Suffix(v any) *Builder { return c.Op(OperatorSuffix, v) }
+
+// Regex matches a case-sensitive regular expression.
+func (c *Condition) Regex(pattern string) *Builder { return c.Op(OperatorRegex, pattern) }
+
+// IsNull matches null or missing values, or the opposite when null is false.
+func (c *Condition) IsNull(null bool) *Builder { return c.Op(OperatorIsNull, null) }
+
+// Exists matches present values, or missing ones when exists is false.
+func (c *Condition) Exists(exists bool) *Builder { return c.Op(OperatorExists, exists) }
+
+// In matches any of the values, they are collected into a typed slice when all have the same type.
+func (c *Condition) In(values ...any) *Builder {
+	return c.Op(OperatorIn, typedSlice(values))
+}
+
+// NotIn matches none of the values, see In.
+func (c *Condition) NotIn(values ...any) *Builder {
+	return c.Op(OperatorNotIn, typedSlice(values))
+}
+
+// All matches slices containing every one of the values.
+func (c *Condition) All(values ...any) *Builder {
+	return c.Op(OperatorAll, typedSlice(values))
+}
+
+// Size matches slices of length n.
+func (c *Condition) Size(n int) *Builder { return c.Op(OperatorSize, n) }
+
+// Between matches values in the inclusive range.
+func (c *Condition) Between(from, to any) *Builder {
+	c.Op(OperatorGreaterOrEqual, from)
+	return c.Op(OperatorLessOrEqual, to)
+}
+
+func typedSlice(values []any) any {
+	if len(values) == 0 {
+		return values
+	}
+	t := reflect.TypeOf(values[0])
+	for _, v := range values {
+		if v == nil || reflect.TypeOf(v) != t {
+			return values
+		}
+	}
+	out := reflect.MakeSlice(reflect.SliceOf(t), 0, len(values))
+	for _, v := range values {
+		out = reflect.Append(out, reflect.ValueOf(v))
+	}
+	return out.Interface()
+}
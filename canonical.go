@@ -0,0 +1,197 @@
+package query
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Canonicalize returns an equivalent query in a normal form, so equal queries compare and hash equal:
+// filters are deduplicated and ordered by field, operator and value (recursively inside groups),
+// "in" lists are sorted and deduplicated, sort keys are merged the way Sort.Set does,
+// select is sorted and times are converted to UTC.
+func (q Query) Canonicalize() Query {
+	out := q.Copy()
+	out.Filter = canonicalFilter(out.Filter)
+
+	s := Sort{}
+	for _, f := range out.Sort {
+		s.SetField(f)
+	}
+	out.Sort = s
+
+	for i, v := range out.Pagination.After {
+		out.Pagination.After[i] = canonicalValue(v)
+	}
+
+	out.Select = SliceUnique(out.Select)
+	slices.Sort(out.Select)
+
+	return out
+}
+
+// Hash returns a hex encoded SHA-256 of the canonical form of the query, stable across processes.
+func (q Query) Hash() (string, error) {
+	data, err := q.Canonicalize().MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func canonicalFilter(f Filter) Filter {
+	if f == nil {
+		return nil
+	}
+
+	type keyed struct {
+		key    string
+		filter FieldFilter
+	}
+	filters := make([]keyed, 0, len(f))
+	seen := map[string]bool{}
+	for _, filter := range f {
+		if group, ok := filter.Group(); ok {
+			groups := make([]Filter, 0, len(group))
+			for _, g := range group {
+				groups = append(groups, canonicalFilter(g))
+			}
+			sort.SliceStable(groups, func(i, j int) bool {
+				return filterKey(groups[i]) < filterKey(groups[j])
+			})
+			filter = FieldFilter{Field: filter.Field, Op: filter.Op, Value: groups}
+		} else if elem, ok := filter.Elem(); ok {
+			filter = ElemMatch(filter.Field, canonicalFilter(elem))
+		} else {
+			filter = FieldFilter{Field: filter.Field, Op: filter.Op, Value: canonicalFilterValue(filter.Op, filter.Value)}
+		}
+
+		key := fieldFilterKey(filter)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		filters = append(filters, keyed{key: key, filter: filter})
+	}
+
+	sort.SliceStable(filters, func(i, j int) bool {
+		return filters[i].key < filters[j].key
+	})
+
+	out := make(Filter, 0, len(filters))
+	for _, k := range filters {
+		out = append(out, k.filter)
+	}
+	return out
+}
+
+// isSetOperator reports whether the order of values in the list doesn't matter for op.
+func isSetOperator(op Operator) bool {
+	return op == OperatorIn || op == OperatorNotIn || op == OperatorAll
+}
+
+func canonicalFilterValue(op Operator, v any) any {
+	if !isSetOperator(op) {
+		return canonicalValue(v)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return canonicalValue(v)
+	}
+
+	out := reflect.MakeSlice(rv.Type(), 0, rv.Len())
+	seen := map[string]bool{}
+	for i := 0; i < rv.Len(); i++ {
+		elem := reflect.ValueOf(canonicalValue(rv.Index(i).Interface()))
+		if !elem.IsValid() {
+			elem = reflect.Zero(rv.Type().Elem())
+		}
+		key := valueKey(elem.Interface())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = reflect.Append(out, elem.Convert(rv.Type().Elem()))
+	}
+	sort.SliceStable(out.Interface(), func(i, j int) bool {
+		return lessValues(out.Index(i).Interface(), out.Index(j).Interface())
+	})
+	return out.Interface()
+}
+
+func canonicalValue(v any) any {
+	if t, ok := v.(time.Time); ok {
+		return t.UTC()
+	}
+	return v
+}
+
+func fieldFilterKey(f FieldFilter) string {
+	if group, ok := f.Group(); ok {
+		keys := make([]string, 0, len(group))
+		for _, g := range group {
+			keys = append(keys, filterKey(g))
+		}
+		return "\x7f" + string(f.Op) + "(" + strings.Join(keys, "|") + ")"
+	}
+	if elem, ok := f.Elem(); ok {
+		return f.Field + "\x00" + string(f.Op) + "\x00" + filterKey(elem)
+	}
+	return f.Field + "\x00" + string(f.Op) + "\x00" + valueKey(f.Value)
+}
+
+func filterKey(f Filter) string {
+	keys := make([]string, 0, len(f))
+	for _, filter := range f {
+		keys = append(keys, fieldFilterKey(filter))
+	}
+	return "[" + strings.Join(keys, ",") + "]"
+}
+
+func valueKey(v any) string {
+	if isNil(v) {
+		return "\x00nil"
+	}
+	if s, err := formatValue(v); err == nil {
+		return reflect.TypeOf(v).String() + ":" + s
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		keys := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			keys = append(keys, valueKey(rv.Index(i).Interface()))
+		}
+		return "[" + strings.Join(keys, ",") + "]"
+	}
+	return reflect.TypeOf(v).String()
+}
+
+// lessValues orders values of the same basic type naturally and anything else by their string form.
+func lessValues(a, b any) bool {
+	ra, rb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if ra.IsValid() && rb.IsValid() && ra.Kind() == rb.Kind() {
+		switch ra.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return ra.Int() < rb.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return ra.Uint() < rb.Uint()
+		case reflect.Float32, reflect.Float64:
+			return ra.Float() < rb.Float()
+		case reflect.String:
+			return ra.String() < rb.String()
+		}
+		ta, okA := a.(time.Time)
+		tb, okB := b.(time.Time)
+		if okA && okB {
+			return ta.Before(tb)
+		}
+	}
+	return valueKey(a) < valueKey(b)
+}
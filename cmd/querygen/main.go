@@ -0,0 +1,454 @@
+// Querygen generates field path constants, a reflection-free filter parser
+// and queryreflect matchers for query models.
+//
+//	//go:generate go run github.com/royalcat/query/cmd/querygen -type Model
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of model type names; must be set")
+	output := flag.String("output", "", "output file name; default <dir>/<type>_query.go")
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("querygen: ")
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if args := flag.Args(); len(args) > 0 {
+		dir = args[0]
+	}
+
+	pkg, err := loadPackage(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, name := range strings.Split(*typeNames, ",") {
+		name = strings.TrimSpace(name)
+		m, err := pkg.model(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		src, err := generate(m)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		out := *output
+		if out == "" {
+			suffix := "_query.go"
+			if m.test {
+				suffix = "_query_test.go"
+			}
+			out = filepath.Join(dir, strings.ToLower(name)+suffix)
+		}
+		if err := os.WriteFile(out, src, 0o644); err != nil { //nolint:gosec
+			log.Fatal(err)
+		}
+	}
+}
+
+type kind int
+
+const (
+	kindString kind = iota + 1
+	kindInt
+	kindUint
+	kindFloat
+	kindBool
+	kindTime
+)
+
+var basicKinds = map[string]kind{
+	"string": kindString,
+	"int":    kindInt, "int8": kindInt, "int16": kindInt, "int32": kindInt, "int64": kindInt, "rune": kindInt,
+	"uint": kindUint, "uint8": kindUint, "uint16": kindUint, "uint32": kindUint, "uint64": kindUint, "byte": kindUint,
+	"float32": kindFloat, "float64": kindFloat,
+	"bool": kindBool,
+}
+
+// step is a struct field selected on the way to a leaf.
+type step struct {
+	name string
+	ptr  bool
+}
+
+type field struct {
+	path  string
+	steps []step
+	kind  kind
+	typ   string // leaf type, element type for slices
+	slice bool
+}
+
+func (f field) constName(model string) string {
+	names := make([]string, 0, len(f.steps))
+	for _, s := range f.steps {
+		names = append(names, strings.ToUpper(s.name[:1])+s.name[1:])
+	}
+	return model + "Field" + strings.Join(names, "")
+}
+
+type model struct {
+	name    string
+	pkg     string
+	test    bool
+	fields  []field
+	skipped []string
+}
+
+type typeSpec struct {
+	spec *ast.TypeSpec
+	file string
+	pkg  string
+}
+
+type pkg struct {
+	types map[string]typeSpec
+	// time is the local name of the time package import per file
+	time map[string]string
+}
+
+func loadPackage(dir string) (*pkg, error) {
+	fset := token.NewFileSet()
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &pkg{types: map[string]typeSpec{}, time: map[string]string{}}
+	for _, name := range matches {
+		f, err := parser.ParseFile(fset, name, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if ast.IsGenerated(f) {
+			continue
+		}
+
+		for _, imp := range f.Imports {
+			if imp.Path.Value != `"time"` {
+				continue
+			}
+			p.time[name] = "time"
+			if imp.Name != nil {
+				p.time[name] = imp.Name.Name
+			}
+		}
+
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				p.types[ts.Name.Name] = typeSpec{spec: ts, file: name, pkg: f.Name.Name}
+			}
+		}
+	}
+	return p, nil
+}
+
+func (p *pkg) model(name string) (*model, error) {
+	ts, ok := p.types[name]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found", name)
+	}
+	st, ok := ts.spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct", name)
+	}
+	if ts.spec.TypeParams != nil {
+		return nil, fmt.Errorf("type %s is generic", name)
+	}
+
+	m := &model{
+		name:    name,
+		pkg:     ts.pkg,
+		test:    strings.HasSuffix(ts.file, "_test.go"),
+		fields:  nil,
+		skipped: nil,
+	}
+	p.walk(m, ts.file, st, "", nil, map[string]bool{name: true})
+	return m, nil
+}
+
+func (p *pkg) walk(m *model, file string, st *ast.StructType, prefix string, steps []step, seen map[string]bool) {
+	for _, f := range st.Fields.List {
+		if f.Tag == nil || len(f.Names) == 0 {
+			continue
+		}
+		tag, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			continue
+		}
+		key, _, _ := strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+
+		for _, n := range f.Names {
+			s := append(steps[:len(steps):len(steps)], step{name: n.Name, ptr: false})
+			p.leaf(m, file, f.Type, prefix+key, s, seen)
+		}
+	}
+}
+
+func (p *pkg) leaf(m *model, file string, expr ast.Expr, path string, steps []step, seen map[string]bool) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		steps[len(steps)-1].ptr = true
+		p.leaf(m, file, t.X, path, steps, seen)
+		return
+	case *ast.StructType:
+		p.walk(m, file, t, path+".", steps, seen)
+		return
+	case *ast.ArrayType:
+		if t.Len == nil {
+			if k, typ, ok := p.basic(file, t.Elt); ok {
+				m.fields = append(m.fields, field{path: path, steps: steps, kind: k, typ: typ, slice: true})
+				return
+			}
+		}
+	case *ast.Ident:
+		if k, typ, ok := p.basic(file, t); ok {
+			m.fields = append(m.fields, field{path: path, steps: steps, kind: k, typ: typ, slice: false})
+			return
+		}
+		if ts, ok := p.types[t.Name]; ok && !seen[t.Name] {
+			if st, ok := ts.spec.Type.(*ast.StructType); ok && ts.spec.TypeParams == nil {
+				seen[t.Name] = true
+				p.walk(m, ts.file, st, path+".", steps, seen)
+				delete(seen, t.Name)
+				return
+			}
+		}
+	case *ast.SelectorExpr:
+		if k, typ, ok := p.basic(file, t); ok {
+			m.fields = append(m.fields, field{path: path, steps: steps, kind: k, typ: typ, slice: false})
+			return
+		}
+	}
+	m.skipped = append(m.skipped, path)
+}
+
+// basic resolves builtin types, time.Time and local types defined over them.
+func (p *pkg) basic(file string, expr ast.Expr) (kind, string, bool) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if k, ok := basicKinds[t.Name]; ok {
+			return k, t.Name, true
+		}
+		ts, ok := p.types[t.Name]
+		if !ok || ts.spec.Assign.IsValid() || ts.spec.TypeParams != nil {
+			return 0, "", false
+		}
+		if u, ok := ts.spec.Type.(*ast.Ident); ok {
+			if k, ok := basicKinds[u.Name]; ok {
+				return k, t.Name, true
+			}
+		}
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		if ok && p.time[file] != "" && x.Name == p.time[file] && t.Sel.Name == "Time" {
+			return kindTime, "time.Time", true
+		}
+	}
+	return 0, "", false
+}
+
+func generate(m *model) ([]byte, error) {
+	var b bytes.Buffer
+	w := func(format string, args ...any) { fmt.Fprintf(&b, format, args...) }
+
+	parseFunc := "Parse" + m.name + "Filter"
+	if !unicode.IsUpper(rune(m.name[0])) {
+		parseFunc = "parse" + strings.ToUpper(m.name[:1]) + m.name[1:] + "Filter"
+	}
+
+	w("// Code generated by querygen; DO NOT EDIT.\n\n")
+	w("package %s\n\nimport (\n", m.pkg)
+	if m.needsCmp() {
+		w("\t\"cmp\"\n\n")
+	}
+	w("\t\"github.com/royalcat/query\"\n\t\"github.com/royalcat/query/queryreflect\"\n)\n\n")
+
+	if len(m.fields) > 0 {
+		w("// %s field paths.\n", m.name)
+		if len(m.skipped) > 0 {
+			w("// Not generated, matched with reflection: %s.\n", strings.Join(m.skipped, ", "))
+		}
+		w("const (\n")
+		for _, f := range m.fields {
+			w("\t%s = %q\n", f.constName(m.name), f.path)
+		}
+		w(")\n\n")
+	}
+
+	w("// %s is query.ParseFilter for %s without reflection,\n", parseFunc, m.name)
+	w("// fields that are not generated fall back to reflection.\n")
+	w("func %s(values map[string][]string, opts ...query.Option) (query.Filter, error) {\n", parseFunc)
+	w("\treturn query.ParseFilterFunc[%s](values, func(", m.name)
+	w("field string, op query.Operator, values []string) (any, error) {\n")
+	w("\t\tswitch field {\n")
+	for _, f := range m.fields {
+		if f.kind == kindTime {
+			// time expressions depend on the parser options
+			continue
+		}
+		if f.slice {
+			w("\t\tcase %s:\n\t\t\treturn query.ParseSliceValue(field, op, values, %s)\n", f.constName(m.name), parseHelper(f))
+		} else {
+			w("\t\tcase %s:\n\t\t\treturn %s(field, op, values)\n", f.constName(m.name), parseHelper(f))
+		}
+	}
+	w("\t\t}\n\t\treturn nil, query.ErrReflectValue\n")
+	w("\t}, opts...)\n}\n\n")
+
+	w("// QueryMatch implements queryreflect.Matcher.\n")
+	w("func (m %s) QueryMatch(f query.FieldFilter) (matched, ok bool) {\n", m.name)
+	w("\tswitch f.Field {\n")
+	for _, f := range m.fields {
+		w("\tcase %s:\n", f.constName(m.name))
+		if cond := nilCheck(f, "m"); cond != "" {
+			w("\t\tif %s {\n\t\t\treturn false, false\n\t\t}\n", cond)
+		}
+		if f.slice {
+			w("\t\treturn queryreflect.MatchSlice(f.Op, %s, f.Value, %s)\n", selector(f, "m"), matchHelper(f, true))
+		} else {
+			w("\t\treturn %s(f.Op, %s, f.Value)\n", matchHelper(f, false), selector(f, "m"))
+		}
+	}
+	w("\t}\n\treturn false, false\n}\n\n")
+
+	w("// QueryCompare implements queryreflect.Comparer.\n")
+	w("func (m %s) QueryCompare(other %s, key string) (c int, ok bool) {\n", m.name, m.name)
+	w("\tswitch key {\n")
+	for _, f := range m.fields {
+		if f.slice || f.kind == kindBool {
+			continue
+		}
+		w("\tcase %s:\n", f.constName(m.name))
+		conds := []string{}
+		for _, c := range []string{nilCheck(f, "m"), nilCheck(f, "other")} {
+			if c != "" {
+				conds = append(conds, c)
+			}
+		}
+		if len(conds) > 0 {
+			w("\t\tif %s {\n\t\t\treturn 0, false\n\t\t}\n", strings.Join(conds, " || "))
+		}
+		if f.kind == kindTime {
+			w("\t\treturn %s.Compare(%s), true\n", selector(f, "m"), selector(f, "other"))
+		} else {
+			w("\t\treturn cmp.Compare(%s, %s), true\n", selector(f, "m"), selector(f, "other"))
+		}
+	}
+	w("\t}\n\treturn 0, false\n}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+func (m *model) hasCompare(k kind) bool {
+	for _, f := range m.fields {
+		if !f.slice && f.kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *model) needsCmp() bool {
+	for _, k := range []kind{kindString, kindInt, kindUint, kindFloat} {
+		if m.hasCompare(k) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseHelper(f field) string {
+	switch f.kind {
+	case kindString:
+		return "query.ParseStringValue[" + f.typ + "]"
+	case kindInt:
+		return "query.ParseIntValue[" + f.typ + "]"
+	case kindUint:
+		return "query.ParseUintValue[" + f.typ + "]"
+	case kindFloat:
+		return "query.ParseFloatValue[" + f.typ + "]"
+	case kindBool:
+		return "query.ParseBoolValue[" + f.typ + "]"
+	default:
+		panic(fmt.Errorf("no parse helper for %s of kind %d", f.path, f.kind))
+	}
+}
+
+func matchHelper(f field, instantiate bool) string {
+	var name string
+	switch f.kind {
+	case kindString:
+		name = "queryreflect.MatchString"
+	case kindBool:
+		name = "queryreflect.MatchBool"
+	case kindTime:
+		return "queryreflect.MatchTime"
+	default:
+		name = "queryreflect.MatchOrdered"
+	}
+	if instantiate {
+		name += "[" + f.typ + "]"
+	}
+	return name
+}
+
+// nilCheck returns a condition that is true when a pointer on the path to f is nil.
+func nilCheck(f field, recv string) string {
+	conds := []string{}
+	expr := recv
+	for _, s := range f.steps {
+		expr += "." + s.name
+		if s.ptr {
+			conds = append(conds, expr+" == nil")
+		}
+	}
+	return strings.Join(conds, " || ")
+}
+
+// selector returns the expression of f, a pointer leaf is dereferenced.
+func selector(f field, recv string) string {
+	expr := recv
+	for _, s := range f.steps {
+		expr += "." + s.name
+	}
+	if f.steps[len(f.steps)-1].ptr {
+		return "*" + expr
+	}
+	return expr
+}
@@ -0,0 +1,197 @@
+package query
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Cursor holds the values of the sort keys of the last item of a page,
+// the next page starts right after it. Sort must define a total order
+// (e.g. end with an unique key) for cursor pagination to be stable.
+type Cursor []any
+
+type cursorToken struct {
+	Keys   []string  `json:"k"`
+	Values []*string `json:"v"`
+}
+
+// EncodeCursor encodes the cursor into an opaque url-safe token,
+// when key is not empty the token is signed with HMAC-SHA256.
+func EncodeCursor(c Cursor, s Sort, key []byte) (string, error) {
+	if len(c) != len(s) {
+		return "", fmt.Errorf("%w: cursor has %d values for %d sort keys", ErrInvalidCursor, len(c), len(s))
+	}
+
+	token := cursorToken{
+		Keys:   make([]string, 0, len(s)),
+		Values: make([]*string, 0, len(c)),
+	}
+	for i, f := range s {
+		token.Keys = append(token.Keys, sortKeyString(f))
+		if isNil(c[i]) {
+			token.Values = append(token.Values, nil)
+			continue
+		}
+		v, err := formatValue(c[i])
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrInvalidCursor, err.Error())
+		}
+		token.Values = append(token.Values, &v)
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+
+	out := base64.RawURLEncoding.EncodeToString(data)
+	if len(key) > 0 {
+		out += "." + base64.RawURLEncoding.EncodeToString(cursorSignature(key, data))
+	}
+	return out, nil
+}
+
+// DecodeCursor decodes a token created by EncodeCursor for the same sort,
+// values are restored to the types of the model fields.
+func DecodeCursor[Model any](token string, s Sort, key []byte) (Cursor, error) {
+	return decodeCursor(modelType[Model](), token, s, key)
+}
+
+func decodeCursor(t reflect.Type, token string, s Sort, key []byte) (Cursor, error) {
+	payload, signature, signed := strings.Cut(token, ".")
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCursor, err.Error())
+	}
+
+	if len(key) > 0 {
+		sig, err := base64.RawURLEncoding.DecodeString(signature)
+		if !signed || err != nil || !hmac.Equal(sig, cursorSignature(key, data)) {
+			return nil, fmt.Errorf("%w: bad signature", ErrInvalidCursor)
+		}
+	}
+
+	var ct cursorToken
+	if err := json.Unmarshal(data, &ct); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCursor, err.Error())
+	}
+
+	if len(ct.Keys) != len(s) || len(ct.Values) != len(s) {
+		return nil, fmt.Errorf("%w: cursor doesn't match sort", ErrInvalidCursor)
+	}
+
+	c := make(Cursor, 0, len(s))
+	for i, f := range s {
+		if ct.Keys[i] != sortKeyString(f) {
+			return nil, fmt.Errorf("%w: cursor doesn't match sort", ErrInvalidCursor)
+		}
+		if ct.Values[i] == nil {
+			c = append(c, nil)
+			continue
+		}
+
+		ft, err := GetTypeByPath(t, f.Key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := parseStringForType(ft, *ct.Values[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCursor, err.Error())
+		}
+		c = append(c, v)
+	}
+	return c, nil
+}
+
+func cursorSignature(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// NewCursor reads the values of the sort keys from item.
+func NewCursor(item any, s Sort) (Cursor, error) {
+	c := make(Cursor, 0, len(s))
+	for _, f := range s {
+		v, err := valueByPath(reflect.ValueOf(item), f.Key)
+		if err != nil {
+			return nil, err
+		}
+		if !v.IsValid() {
+			c = append(c, nil)
+			continue
+		}
+		c = append(c, v.Interface())
+	}
+	return c, nil
+}
+
+// NextCursor returns the cursor of the page following page,
+// or nil when page is the last one.
+func NextCursor[T any](q Query, page []T) (Cursor, error) {
+	if q.Pagination.Limit == 0 || uint64(len(page)) < q.Pagination.Limit || len(page) == 0 {
+		return nil, nil
+	}
+	return NewCursor(page[len(page)-1], q.Sort)
+}
+
+// valueByPath returns the first value found by path, or invalid value when there is none.
+func valueByPath(v reflect.Value, path string) (reflect.Value, error) {
+	parts := strings.Split(path, ".")
+
+	for i := 0; i < len(parts); {
+		switch v.Kind() {
+		case reflect.Struct:
+			f, found := getFieldByJsonTag(v.Type(), parts[i])
+			if !found {
+				return reflect.Value{}, unknownPathPart(path, parts[i])
+			}
+			v = v.FieldByIndex(f.Index)
+			i++
+		case reflect.Slice, reflect.Array:
+			idx := 0
+			if n, err := strconv.Atoi(parts[i]); err == nil {
+				idx = n
+				i++
+			}
+			if idx >= v.Len() {
+				return reflect.Value{}, nil
+			}
+			v = v.Index(idx)
+		case reflect.Pointer, reflect.Interface:
+			if v.IsNil() {
+				return reflect.Value{}, nil
+			}
+			v = v.Elem()
+		default:
+			return reflect.Value{}, unknownPathPart(path, parts[i])
+		}
+	}
+
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, nil
+		}
+		v = v.Elem()
+	}
+	return v, nil
+}
+
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		return rv.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Values encodes the query to url parameters in the syntax ParseQuery accepts.
+// Options set the parameter names and the cursor key the same way they do for parsing.
+func (q Query) Values(opts ...Option) (url.Values, error) {
+	o := newOptions(opts)
+	v := url.Values{}
+
+	for _, f := range q.Filter {
+		if isGroupOperator(f.Op) {
+			return nil, fmt.Errorf("%w: group operator %s", ErrNotEncodable, f.Op)
+		}
+		if o.params.isReserved(f.Field) {
+			return nil, fmt.Errorf("%w: field %s is a reserved parameter", ErrNotEncodable, f.Field)
+		}
+		if err := encodeFilter(v, "", f); err != nil {
+			return nil, err
+		}
+	}
+
+	if q.Search != "" {
+		v.Set(o.params.Search, q.Search)
+	}
+	if len(q.Sort) > 0 {
+		keys := make([]string, 0, len(q.Sort))
+		for _, f := range q.Sort {
+			keys = append(keys, sortKeyString(f))
+		}
+		v.Set(o.params.Sort, strings.Join(keys, ","))
+	}
+	if len(q.Select) > 0 {
+		v.Set(o.params.Select, strings.Join(q.Select, ","))
+	}
+	if q.Pagination.Offset != 0 {
+		v.Set(o.params.Offset, strconv.FormatUint(q.Pagination.Offset, 10))
+	}
+	if q.Pagination.Limit != 0 {
+		v.Set(o.params.Limit, strconv.FormatUint(q.Pagination.Limit, 10))
+	}
+	if len(q.Pagination.After) > 0 {
+		c, err := EncodeCursor(q.Pagination.After, q.Sort, o.cursorKey)
+		if err != nil {
+			return nil, err
+		}
+		v.Set(o.params.Cursor, c)
+	}
+
+	return v, nil
+}
+
+// Encode encodes the query to an url query string with keys in sorted order.
+func (q Query) Encode(opts ...Option) (string, error) {
+	v, err := q.Values(opts...)
+	if err != nil {
+		return "", err
+	}
+	return v.Encode(), nil
+}
+
+func (q Query) String() string {
+	s, err := q.Encode()
+	if err != nil {
+		return fmt.Sprintf("invalid query: %s", err.Error())
+	}
+	return s
+}
+
+// encodeFilter adds a condition to v, prefix is the key of the element filter
+// holding it: conditions on an element are keyed like "items{elemMatch}.price{gte}".
+func encodeFilter(v url.Values, prefix string, f FieldFilter) error {
+	key := encodeMapKey(f.Field, f.Op)
+	if prefix != "" && f.Field != "" {
+		key = prefix + "." + key
+	} else {
+		key = prefix + key
+	}
+
+	if elem, ok := f.Elem(); ok {
+		if len(elem) == 0 {
+			return fmt.Errorf("%w: empty %s of %s", ErrNotEncodable, OperatorElemMatch, f.Field)
+		}
+		for _, e := range elem {
+			if isGroupOperator(e.Op) {
+				return fmt.Errorf("%w: group operator %s in %s", ErrNotEncodable, e.Op, OperatorElemMatch)
+			}
+			if err := encodeFilter(v, key, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	value, err := encodeFilterValue(f)
+	if err != nil {
+		return &FieldError{Err: ErrInvalidValue, Field: f.Field, Op: f.Op, Value: "", Cause: err}
+	}
+	v.Add(key, value)
+	return nil
+}
+
+func encodeMapKey(name string, op Operator) string {
+	if op == OperatorDefault {
+		return name
+	}
+	return name + "{" + string(op) + "}"
+}
+
+func encodeFilterValue(f FieldFilter) (string, error) {
+	if !isListOperator(f.Op) {
+		return formatFilterValue(f.Op, f.Value)
+	}
+
+	rv := reflect.ValueOf(f.Value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return "", fmt.Errorf("value of %s must be a slice, got %T", f.Op, f.Value)
+	}
+	vals := make([]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		s, err := formatValue(rv.Index(i).Interface())
+		if err != nil {
+			return "", err
+		}
+		vals = append(vals, s)
+	}
+	return joinList(vals), nil
+}
@@ -0,0 +1,119 @@
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kinds of FieldError, check them with errors.Is.
+var (
+	ErrUnknownField       = errors.New("unknown field")
+	ErrUnknownOperator    = errors.New("unknown operator")
+	ErrOperatorNotAllowed = errors.New("operator not allowed")
+	ErrInvalidValue       = errors.New("invalid value")
+	ErrFieldNotAllowed    = errors.New("field not allowed")
+	ErrSortNotAllowed     = errors.New("sort not allowed")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrNotEncodable       = errors.New("query can't be encoded to url")
+	ErrLimitExceeded      = errors.New("query limit exceeded")
+)
+
+// FieldError describes a single invalid parameter of a query.
+type FieldError struct {
+	Err   error
+	Field string
+	Op    Operator
+	Value string
+	Cause error
+}
+
+func (e *FieldError) Error() string {
+	msg := e.Err.Error()
+	if e.Field != "" {
+		msg += fmt.Sprintf(" %q", e.Field)
+	}
+	if e.Op != OperatorDefault {
+		msg += fmt.Sprintf(" operator %q", e.Op)
+	}
+	if e.Value != "" {
+		msg += fmt.Sprintf(" value %q", e.Value)
+	}
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+func (e *FieldError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.Cause}
+}
+
+func (e *FieldError) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Error   string   `json:"error"`
+		Field   string   `json:"field,omitempty"`
+		Op      Operator `json:"op,omitempty"`
+		Value   string   `json:"value,omitempty"`
+		Message string   `json:"message"`
+	}{
+		Error:   e.Err.Error(),
+		Field:   e.Field,
+		Op:      e.Op,
+		Value:   e.Value,
+		Message: e.Error(),
+	}
+	return json.Marshal(out)
+}
+
+// Errors is returned when parsing with WithCollectErrors, it holds every error found in one pass
+// in the order of the (sorted) parameters.
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e Errors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+type errorCollector struct {
+	collect bool
+	errs    Errors
+}
+
+// add records err and reports whether parsing must stop.
+func (c *errorCollector) add(err error) bool {
+	if err == nil {
+		return false
+	}
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		fieldErr = &FieldError{Err: ErrInvalidValue, Field: "", Op: OperatorDefault, Value: "", Cause: err}
+	}
+	c.errs = append(c.errs, fieldErr)
+	return !c.collect
+}
+
+func (c *errorCollector) err() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	if !c.collect {
+		return c.errs[0]
+	}
+	return c.errs
+}
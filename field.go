@@ -1,7 +1,9 @@
 package query
 
 import (
+	"reflect"
 	"strings"
+	"time"
 )
 
 type Fields []string
@@ -18,3 +20,39 @@ func (f Fields) GroupFilterByPrefix(prefix string) (Fields, Fields) {
 	}
 	return g, r
 }
+
+// walkFields calls fn for every json-tagged field of t and of structs nested in it,
+// fn returns false to skip the fields nested in the current one.
+func walkFields(t reflect.Type, fn func(path string, field reflect.StructField) (bool, error)) error {
+	return walkFieldsPrefix(t, "", map[reflect.Type]bool{}, fn)
+}
+
+func walkFieldsPrefix(t reflect.Type, prefix string, visited map[reflect.Type]bool, fn func(path string, field reflect.StructField) (bool, error)) error {
+	t = elemType(t)
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) || visited[t] {
+		return nil
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		path := prefix + name
+
+		descend, err := fn(path, field)
+		if err != nil {
+			return err
+		}
+		if !descend {
+			continue
+		}
+		if err := walkFieldsPrefix(field.Type, path+".", visited, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FieldRef is a filter value referencing another field of the same document,
+// written as "$field" in parameters, "$$" escapes a literal "$".
+// It's accepted by comparison operators: eq, ne, gt, gte, lt and lte.
+type FieldRef string
+
+func isRefOperator(op Operator) bool {
+	switch op {
+	case OperatorDefault, OperatorEqual, OperatorNotEqual,
+		OperatorGreater, OperatorGreaterOrEqual, OperatorLess, OperatorLessOrEqual:
+		return true
+	}
+	return false
+}
+
+// splitFieldRef detects a field reference in values of op, otherwise
+// it returns values with the "$$" escape removed.
+func splitFieldRef(op Operator, values []string) (FieldRef, []string, bool) {
+	if !isRefOperator(op) || len(values) != 1 || !strings.HasPrefix(values[0], "$") {
+		return "", values, false
+	}
+	if strings.HasPrefix(values[0], "$$") {
+		return "", []string{values[0][1:]}, false
+	}
+	return FieldRef(values[0][1:]), nil, true
+}
+
+// fieldRef checks that ref exists and can be compared with the field.
+func (p *parser) fieldRef(name string, op Operator, ref FieldRef) (FieldRef, error) {
+	t, err := p.fieldType(name, op)
+	if err != nil {
+		return "", err
+	}
+	rt, err := GetTypeByPath(p.t, string(ref))
+	if err != nil {
+		return "", err
+	}
+	if p.opts.policy != nil {
+		if fp, ok := p.opts.policy.FieldPolicy(string(ref)); !ok || !fp.Filter {
+			return "", &FieldError{Err: ErrFieldNotAllowed, Field: string(ref), Op: op, Value: "", Cause: nil}
+		}
+	}
+	if !comparableTypes(t, rt) {
+		return "", &FieldError{
+			Err: ErrInvalidValue, Field: name, Op: op, Value: "$" + string(ref),
+			Cause: fmt.Errorf("%s can't be compared with %s", rt, t),
+		}
+	}
+	return ref, nil
+}
+
+// comparableTypes reports whether values of the types can be compared by the backends,
+// they must have the same kind and structs the same type.
+func comparableTypes(a, b reflect.Type) bool {
+	a, b = elemType(a), elemType(b)
+	return a.Kind() == b.Kind() && (a.Kind() != reflect.Struct || a == b)
+}
+
+// formatFilterValue formats a single filter value, field references get
+// the "$" prefix and literal values starting with "$" are escaped.
+func formatFilterValue(op Operator, v any) (string, error) {
+	if ref, ok := v.(FieldRef); ok {
+		return "$" + string(ref), nil
+	}
+	s, err := formatValue(v)
+	if err != nil {
+		return "", err
+	}
+	if isRefOperator(op) && strings.HasPrefix(s, "$") {
+		s = "$" + s
+	}
+	return s, nil
+}
@@ -4,6 +4,9 @@ import (
 	"reflect"
 )
 
+// Filter is a list of conditions that must all match. Group nodes created
+// with And, Or and Not can be mixed with plain field conditions to build
+// arbitrary boolean trees.
 type Filter []FieldFilter
 
 type FieldFilter struct {
@@ -12,9 +15,88 @@ type FieldFilter struct {
 	Value any
 }
 
+// And matches when every one of the filters matches.
+func And(filters ...Filter) FieldFilter {
+	return FieldFilter{Field: "", Op: OperatorAnd, Value: filters}
+}
+
+// Or matches when at least one of the filters matches.
+func Or(filters ...Filter) FieldFilter {
+	return FieldFilter{Field: "", Op: OperatorOr, Value: filters}
+}
+
+// Not matches when none of the filters match.
+func Not(filters ...Filter) FieldFilter {
+	return FieldFilter{Field: "", Op: OperatorNot, Value: filters}
+}
+
+// ElemMatch matches when a single element of the slice field matches every
+// condition of filter. Paths in filter are relative to the element, an empty
+// field is the element itself. In url parameters the conditions are keyed
+// "items{elemMatch}.price{gte}" and "scores{elemMatch}{gt}" for the element itself.
+func ElemMatch(field string, filter Filter) FieldFilter {
+	return FieldFilter{Field: field, Op: OperatorElemMatch, Value: filter}
+}
+
+// Elem returns the element filter of a node created with ElemMatch.
+func (f FieldFilter) Elem() (Filter, bool) {
+	if f.Op != OperatorElemMatch {
+		return nil, false
+	}
+	filter, ok := f.Value.(Filter)
+	return filter, ok
+}
+
+// elemPath joins a slice field with a path relative to its element.
+func elemPath(field, path string) string {
+	if path == "" {
+		return field
+	}
+	return field + "." + path
+}
+
+// mapFields returns a copy of the filter with fn applied to every field,
+// element filters are relative and left as is.
+func (q Filter) mapFields(fn func(string) string) Filter {
+	out := make(Filter, 0, len(q))
+	for _, f := range q {
+		if group, ok := f.Group(); ok {
+			groups := make([]Filter, 0, len(group))
+			for _, g := range group {
+				groups = append(groups, g.mapFields(fn))
+			}
+			out = append(out, FieldFilter{Field: f.Field, Op: f.Op, Value: groups})
+			continue
+		}
+		out = append(out, FieldFilter{Field: fn(f.Field), Op: f.Op, Value: f.Value})
+	}
+	return out
+}
+
+// Group returns nested filters of a group node created with And, Or or Not.
+func (f FieldFilter) Group() ([]Filter, bool) {
+	if !isGroupOperator(f.Op) {
+		return nil, false
+	}
+	filters, ok := f.Value.([]Filter)
+	return filters, ok
+}
+
 func (q Filter) Fields() Fields {
 	fields := []string{}
 	for _, f := range q {
+		if group, ok := f.Group(); ok {
+			for _, g := range group {
+				fields = append(fields, g.Fields()...)
+			}
+			continue
+		}
+		if elem, ok := f.Elem(); ok {
+			for _, path := range elem.Fields() {
+				fields = append(fields, elemPath(f.Field, path))
+			}
+			continue
+		}
 		fields = append(fields, f.Field)
 	}
 	return fields
@@ -23,12 +105,61 @@ func (q Filter) Fields() Fields {
 func (q Filter) Operators() map[string]Operator {
 	ops := map[string]Operator{}
 	for _, f := range q {
+		if group, ok := f.Group(); ok {
+			for _, g := range group {
+				for field, op := range g.Operators() {
+					ops[field] = op
+				}
+			}
+			continue
+		}
+		if elem, ok := f.Elem(); ok {
+			for path, op := range elem.Operators() {
+				ops[elemPath(f.Field, path)] = op
+			}
+			continue
+		}
 		// name, operator := ParseKey(k)
 		ops[f.Field] = f.Op
 	}
 	return ops
 }
 
+// FieldOperators returns every operator used on each field, in filter order.
+// Unlike Operators it keeps all conditions of a range like "age{gte}&age{lte}".
+func (q Filter) FieldOperators() map[string][]Operator {
+	ops := map[string][]Operator{}
+	for _, f := range q {
+		if group, ok := f.Group(); ok {
+			for _, g := range group {
+				for field, fieldOps := range g.FieldOperators() {
+					ops[field] = append(ops[field], fieldOps...)
+				}
+			}
+			continue
+		}
+		if elem, ok := f.Elem(); ok {
+			for path, fieldOps := range elem.FieldOperators() {
+				ops[elemPath(f.Field, path)] = append(ops[elemPath(f.Field, path)], fieldOps...)
+			}
+			continue
+		}
+		ops[f.Field] = append(ops[f.Field], f.Op)
+	}
+	return ops
+}
+
+// ByField returns top level conditions on the given field.
+func (q Filter) ByField(field string) Filter {
+	out := Filter{}
+	for _, f := range q {
+		if f.Field == field && !isGroupOperator(f.Op) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
 // func ParseKey(key FilterKey) (name string, operator Operator) {
 // 	opStart := strings.Index(key, "{")
 
@@ -0,0 +1,58 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// formatValue is the inverse of parseStringForType.
+func formatValue(v any) (string, error) {
+	switch v := v.(type) {
+	case Marshaler:
+		return v.QueryMarshal()
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case json.Marshaler:
+		data, err := v.MarshalJSON()
+		if err != nil {
+			return "", fmt.Errorf("json.Marshal error: %s", err.Error())
+		}
+		if s, err := strconv.Unquote(string(data)); err == nil {
+			return s, nil
+		}
+		return strings.Trim(string(data), "\""), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Pointer:
+		if !rv.IsNil() {
+			return formatValue(rv.Elem().Interface())
+		}
+	}
+	return "", fmt.Errorf("unsupported value type: %T", v)
+}
+
+// joinList is the inverse of splitList.
+func joinList(vals []string) string {
+	escaped := make([]string, 0, len(vals))
+	for _, v := range vals {
+		v = strings.ReplaceAll(v, "\\", "\\\\")
+		escaped = append(escaped, strings.ReplaceAll(v, ",", "\\,"))
+	}
+	return strings.Join(escaped, ",")
+}
@@ -0,0 +1,386 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// JSONVersion is the version of the JSON schema of Query:
+//
+//	{
+//	  "v": 1,
+//	  "search": "text",
+//	  "filter": [
+//	    {"field": "name", "op": "substr", "value": "x"},
+//	    {"field": "id", "op": "in", "values": ["1", "2"]},
+//	    {"op": "or", "groups": [[{"field": "a", "value": "1"}], [{"field": "b", "value": "2"}]]},
+//	    {"field": "items", "op": "elemMatch", "filter": [{"field": "price", "op": "gte", "value": "10"}]}
+//	  ],
+//	  "sort": [{"key": "created_at", "order": "desc"}, {"key": "due_date", "order": "asc", "nulls": "last"}],
+//	  "select": ["id", "name"],
+//	  "offset": 0,
+//	  "limit": 10,
+//	  "after": ["2024-05-01T00:00:00Z", null]
+//	}
+//
+// Values are strings in the same format as in url parameters, so no precision or type is lost
+// in transit. UnmarshalJSON keeps them as string and []string, use Decode to restore model types.
+const JSONVersion = 1
+
+type jsonQuery struct {
+	Version int          `json:"v"`
+	Search  string       `json:"search,omitempty"`
+	Filter  []jsonFilter `json:"filter,omitempty"`
+	Sort    []jsonSort   `json:"sort,omitempty"`
+	Select  []string     `json:"select,omitempty"`
+	Offset  uint64       `json:"offset,omitempty"`
+	Limit   uint64       `json:"limit,omitempty"`
+	After   []*string    `json:"after,omitempty"`
+}
+
+type jsonFilter struct {
+	Field  string         `json:"field,omitempty"`
+	Op     Operator       `json:"op,omitempty"`
+	Value  *string        `json:"value,omitempty"`
+	Values []string       `json:"values,omitempty"`
+	Groups [][]jsonFilter `json:"groups,omitempty"`
+	Filter []jsonFilter   `json:"filter,omitempty"`
+}
+
+type jsonSort struct {
+	Key   string `json:"key"`
+	Order string `json:"order"`
+	Nulls string `json:"nulls,omitempty"`
+}
+
+func (q Query) MarshalJSON() ([]byte, error) {
+	filter, err := marshalFilter(q.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sort := make([]jsonSort, 0, len(q.Sort))
+	for _, f := range q.Sort {
+		order := "asc"
+		if f.Order == DESC {
+			order = "desc"
+		}
+		nulls := ""
+		switch f.Nulls {
+		case NullsFirst:
+			nulls = "first"
+		case NullsLast:
+			nulls = "last"
+		}
+		sort = append(sort, jsonSort{Key: f.Key, Order: order, Nulls: nulls})
+	}
+
+	after, err := marshalValues(q.Pagination.After)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonQuery{
+		Version: JSONVersion,
+		Search:  q.Search,
+		Filter:  filter,
+		Sort:    sort,
+		Select:  q.Select,
+		Offset:  q.Pagination.Offset,
+		Limit:   q.Pagination.Limit,
+		After:   after,
+	})
+}
+
+func marshalFilter(f Filter) ([]jsonFilter, error) {
+	out := make([]jsonFilter, 0, len(f))
+	for _, filter := range f {
+		jf := jsonFilter{Field: filter.Field, Op: filter.Op, Value: nil, Values: nil, Groups: nil, Filter: nil}
+
+		if elem, ok := filter.Elem(); ok {
+			je, err := marshalFilter(elem)
+			if err != nil {
+				return nil, err
+			}
+			jf.Filter = je
+			out = append(out, jf)
+			continue
+		}
+
+		if group, ok := filter.Group(); ok {
+			jf.Groups = make([][]jsonFilter, 0, len(group))
+			for _, g := range group {
+				jg, err := marshalFilter(g)
+				if err != nil {
+					return nil, err
+				}
+				jf.Groups = append(jf.Groups, jg)
+			}
+			out = append(out, jf)
+			continue
+		}
+
+		if isListOperator(filter.Op) {
+			rv := reflect.ValueOf(filter.Value)
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				return nil, &FieldError{Err: ErrInvalidValue, Field: filter.Field, Op: filter.Op, Value: "", Cause: fmt.Errorf("value must be a slice, got %T", filter.Value)}
+			}
+			jf.Values = make([]string, 0, rv.Len())
+			for i := 0; i < rv.Len(); i++ {
+				s, err := formatValue(rv.Index(i).Interface())
+				if err != nil {
+					return nil, &FieldError{Err: ErrInvalidValue, Field: filter.Field, Op: filter.Op, Value: "", Cause: err}
+				}
+				jf.Values = append(jf.Values, s)
+			}
+		} else {
+			s, err := formatFilterValue(filter.Op, filter.Value)
+			if err != nil {
+				return nil, &FieldError{Err: ErrInvalidValue, Field: filter.Field, Op: filter.Op, Value: "", Cause: err}
+			}
+			jf.Value = &s
+		}
+		out = append(out, jf)
+	}
+	return out, nil
+}
+
+func marshalValues(vals []any) ([]*string, error) {
+	if len(vals) == 0 {
+		return nil, nil
+	}
+	out := make([]*string, 0, len(vals))
+	for _, v := range vals {
+		if isNil(v) {
+			out = append(out, nil)
+			continue
+		}
+		s, err := formatValue(v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &s)
+	}
+	return out, nil
+}
+
+// UnmarshalJSON reads the JSON schema described by JSONVersion. Filter and cursor values
+// are left as string (and []string for lists), use Decode to get them as model types.
+func (q *Query) UnmarshalJSON(data []byte) error {
+	var jq jsonQuery
+	if err := json.Unmarshal(data, &jq); err != nil {
+		return err
+	}
+	if jq.Version != JSONVersion {
+		return fmt.Errorf("unsupported query json version: %d", jq.Version)
+	}
+
+	filter, err := unmarshalFilter(jq.Filter)
+	if err != nil {
+		return err
+	}
+
+	sort := make(Sort, 0, len(jq.Sort))
+	for _, s := range jq.Sort {
+		f := SortField{Key: s.Key, Order: ASC, Nulls: NullsDefault}
+		switch s.Order {
+		case "asc":
+		case "desc":
+			f.Order = DESC
+		default:
+			return fmt.Errorf("unknown sort order: %s", s.Order)
+		}
+		switch s.Nulls {
+		case "":
+		case "first":
+			f.Nulls = NullsFirst
+		case "last":
+			f.Nulls = NullsLast
+		default:
+			return fmt.Errorf("unknown null order: %s", s.Nulls)
+		}
+		sort = append(sort, f)
+	}
+
+	var after Cursor
+	for _, v := range jq.After {
+		if v == nil {
+			after = append(after, nil)
+		} else {
+			after = append(after, *v)
+		}
+	}
+
+	*q = Query{
+		Search: jq.Search,
+		Filter: filter,
+		Sort:   sort,
+		Pagination: Pagination{
+			Offset: jq.Offset,
+			Limit:  jq.Limit,
+			After:  after,
+			Clamp:  PaginationClamp{},
+		},
+		Select: jq.Select,
+	}
+	return nil
+}
+
+func unmarshalFilter(jf []jsonFilter) (Filter, error) {
+	f := make(Filter, 0, len(jf))
+	for _, filter := range jf {
+		if isGroupOperator(filter.Op) {
+			groups := make([]Filter, 0, len(filter.Groups))
+			for _, g := range filter.Groups {
+				group, err := unmarshalFilter(g)
+				if err != nil {
+					return nil, err
+				}
+				groups = append(groups, group)
+			}
+			f = append(f, FieldFilter{Field: "", Op: filter.Op, Value: groups})
+			continue
+		}
+		if filter.Op == OperatorElemMatch {
+			elem, err := unmarshalFilter(filter.Filter)
+			if err != nil {
+				return nil, err
+			}
+			f = append(f, ElemMatch(filter.Field, elem))
+			continue
+		}
+
+		switch {
+		case isListOperator(filter.Op):
+			values := filter.Values
+			if values == nil {
+				values = []string{}
+			}
+			f = append(f, FieldFilter{Field: filter.Field, Op: filter.Op, Value: values})
+		case filter.Value != nil:
+			f = append(f, FieldFilter{Field: filter.Field, Op: filter.Op, Value: *filter.Value})
+		default:
+			return nil, &FieldError{Err: ErrInvalidValue, Field: filter.Field, Op: filter.Op, Value: "", Cause: fmt.Errorf("missing value")}
+		}
+	}
+	return f, nil
+}
+
+// Decode reads a query from the JSON schema described by JSONVersion and restores values
+// to the types of the model fields. The query is validated and normalized like a parsed one.
+func Decode[Model any](data []byte, opts ...Option) (Query, error) {
+	var q Query
+	if err := json.Unmarshal(data, &q); err != nil {
+		return Query{}, err
+	}
+
+	p := newParser[Model](opts)
+	// check limits before values are parsed
+	if err := p.opts.limits.Check(q); err != nil {
+		p.errs.add(err)
+		return q, p.errs.err()
+	}
+	q = p.typed(q).Normalize(p.opts.pagination)
+	if err := p.errs.err(); err != nil {
+		return q, err
+	}
+	p.validate(q)
+	return q, p.errs.err()
+}
+
+// typed converts string values of an unmarshaled query to the model types.
+func (p *parser) typed(q Query) Query {
+	f, ok := p.typedFilter(q.Filter)
+	q.Filter = f
+	if !ok || len(q.Pagination.After) == 0 {
+		return q
+	}
+
+	if len(q.Pagination.After) != len(q.Sort) {
+		p.errs.add(&FieldError{Err: ErrInvalidValue, Field: p.opts.params.Cursor, Op: OperatorDefault, Value: "", Cause: fmt.Errorf("%w: cursor doesn't match sort", ErrInvalidCursor)})
+		return q
+	}
+	for i, v := range q.Pagination.After {
+		s, isString := v.(string)
+		if !isString {
+			continue
+		}
+		t, err := GetTypeByPath(p.t, q.Sort[i].Key)
+		if err != nil {
+			if p.errs.add(err) {
+				return q
+			}
+			continue
+		}
+		val, err := parseStringForType(t, s)
+		if err != nil {
+			if p.errs.add(&FieldError{Err: ErrInvalidValue, Field: p.opts.params.Cursor, Op: OperatorDefault, Value: s, Cause: err}) {
+				return q
+			}
+			continue
+		}
+		q.Pagination.After[i] = val
+	}
+	return q
+}
+
+func (p *parser) typedFilter(f Filter) (Filter, bool) {
+	out := make(Filter, 0, len(f))
+	for _, filter := range f {
+		if group, ok := filter.Group(); ok {
+			groups := make([]Filter, 0, len(group))
+			for _, g := range group {
+				tg, ok := p.typedFilter(g)
+				groups = append(groups, tg)
+				if !ok {
+					return out, false
+				}
+			}
+			out = append(out, FieldFilter{Field: filter.Field, Op: filter.Op, Value: groups})
+			continue
+		}
+		if elem, ok := filter.Elem(); ok {
+			if _, err := p.sliceType(filter.Field); err != nil {
+				if p.errs.add(err) {
+					return out, false
+				}
+				continue
+			}
+			field := filter.Field
+			typed, ok := p.typedFilter(elem.mapFields(func(path string) string {
+				return elemPath(field, path)
+			}))
+			out = append(out, ElemMatch(field, typed.mapFields(func(path string) string {
+				if path == field {
+					return ""
+				}
+				return strings.TrimPrefix(path, field+".")
+			})))
+			if !ok {
+				return out, false
+			}
+			continue
+		}
+
+		var val any
+		var err error
+		switch v := filter.Value.(type) {
+		case string:
+			val, err = p.fieldValue(filter.Field, filter.Op, []string{v})
+		case []string:
+			val, err = p.fieldValue(filter.Field, filter.Op, v)
+		default:
+			val, err = v, p.checkValue(filter)
+		}
+		if err != nil {
+			if p.errs.add(err) {
+				return out, false
+			}
+			continue
+		}
+		out = append(out, FieldFilter{Field: filter.Field, Op: filter.Op, Value: val})
+	}
+	return out, true
+}
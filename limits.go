@@ -0,0 +1,163 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Limits is a complexity budget of a query, zero values disable a limit.
+type Limits struct {
+	// MaxFilters limits the number of conditions, group and elemMatch nodes count with their contents.
+	MaxFilters int
+	// MaxValues limits the number of values of a list operator such as "in",
+	// repeated keys of other operators are separate conditions counted by MaxFilters.
+	MaxValues int
+	// MaxDepth limits the number of segments of field paths in filter, sort and select.
+	MaxDepth int
+	// MaxPattern limits the length of substr, prefix, suffix and regex values
+	// and of Query.Search, which is matched as unanchored patterns too.
+	MaxPattern int
+	// MaxSortKeys limits the number of sort keys.
+	MaxSortKeys int
+}
+
+// LimitError is the cause of ErrLimitExceeded, Limit is the name of the exceeded field of Limits.
+type LimitError struct {
+	Limit string
+	Max   int
+	Got   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s is %d, got %d", e.Limit, e.Max, e.Got)
+}
+
+func limitExceeded(limit string, max, got int, field string, op Operator) error {
+	if max == 0 || got <= max {
+		return nil
+	}
+	return &FieldError{
+		Err: ErrLimitExceeded, Field: field, Op: op, Value: "",
+		Cause: &LimitError{Limit: limit, Max: max, Got: got},
+	}
+}
+
+func isPatternOperator(op Operator) bool {
+	return op == OperatorSubString || op == OperatorPrefix || op == OperatorSuffix || op == OperatorRegex
+}
+
+func (l Limits) checkFilters(n int) error {
+	return limitExceeded("MaxFilters", l.MaxFilters, n, "", OperatorDefault)
+}
+
+func (l Limits) checkValues(field string, op Operator, n int) error {
+	return limitExceeded("MaxValues", l.MaxValues, n, field, op)
+}
+
+func (l Limits) checkDepth(field string) error {
+	if l.MaxDepth == 0 {
+		return nil
+	}
+	return limitExceeded("MaxDepth", l.MaxDepth, strings.Count(field, ".")+1, field, OperatorDefault)
+}
+
+func (l Limits) checkPattern(field string, op Operator, v string) error {
+	if !isPatternOperator(op) {
+		return nil
+	}
+	return limitExceeded("MaxPattern", l.MaxPattern, len(v), field, op)
+}
+
+func (l Limits) checkSearch(name, search string) error {
+	return limitExceeded("MaxPattern", l.MaxPattern, len(search), name, OperatorDefault)
+}
+
+func (l Limits) checkSortKeys(n int) error {
+	return limitExceeded("MaxSortKeys", l.MaxSortKeys, n, "", OperatorDefault)
+}
+
+// Check checks a query against the limits, e.g. one built in code.
+// Parsing with WithLimits checks them before values are parsed.
+func (l Limits) Check(q Query) error {
+	if err := l.checkFilters(countFilters(q.Filter)); err != nil {
+		return err
+	}
+	if err := l.checkFilter(q.Filter, ""); err != nil {
+		return err
+	}
+	if err := l.checkSearch(DefaultParamNames.Search, q.Search); err != nil {
+		return err
+	}
+	if err := l.checkSortKeys(len(q.Sort)); err != nil {
+		return err
+	}
+	for _, f := range q.Sort {
+		if err := l.checkDepth(f.Key); err != nil {
+			return err
+		}
+	}
+	for _, f := range q.Select {
+		if err := l.checkDepth(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l Limits) checkFilter(q Filter, prefix string) error {
+	for _, f := range q {
+		if group, ok := f.Group(); ok {
+			for _, g := range group {
+				if err := l.checkFilter(g, prefix); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		field := f.Field
+		if prefix != "" {
+			field = elemPath(prefix, f.Field)
+		}
+		if err := l.checkDepth(field); err != nil {
+			return err
+		}
+		if elem, ok := f.Elem(); ok {
+			if err := l.checkFilter(elem, field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if isListOperator(f.Op) {
+			if rv := reflect.ValueOf(f.Value); rv.Kind() == reflect.Slice {
+				if err := l.checkValues(field, f.Op, rv.Len()); err != nil {
+					return err
+				}
+			}
+		}
+		if s, ok := f.Value.(string); ok {
+			if err := l.checkPattern(field, f.Op, s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// countFilters counts conditions of the filter including group and elemMatch nodes.
+func countFilters(q Filter) int {
+	n := 0
+	for _, f := range q {
+		n++
+		if group, ok := f.Group(); ok {
+			for _, g := range group {
+				n += countFilters(g)
+			}
+		} else if elem, ok := f.Elem(); ok {
+			n += countFilters(elem)
+		}
+	}
+	return n
+}
@@ -0,0 +1,148 @@
+package query
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Merge combines two queries: filters of both must match, sort keys of o are appended
+// after the keys of q unless q has a cursor, non-empty search, select and pagination values of o override q.
+func (q Query) Merge(o Query) Query {
+	out := q.Copy()
+	out.Filter = append(out.Filter, o.Filter...)
+	out.Sort = cursorSort(out, o.Sort)
+
+	if o.Search != "" {
+		out.Search = o.Search
+	}
+	if len(o.Select) > 0 {
+		out.Select = slices.Clone(o.Select)
+	}
+	if o.Pagination.Offset != 0 {
+		out.Pagination.Offset = o.Pagination.Offset
+	}
+	if o.Pagination.Limit != 0 {
+		out.Pagination.Limit = o.Pagination.Limit
+	}
+	if len(o.Pagination.After) > 0 {
+		out.Pagination.After = slices.Clone(o.Pagination.After)
+	}
+	return out
+}
+
+// Conflict is a user filter dropped by Scope in favor of a scope filter
+// with the same field and operator but a different value.
+type Conflict struct {
+	Scope   FieldFilter
+	Dropped FieldFilter
+}
+
+// Scope applies server side constraints (tenant, owner, soft-delete...) to a user query, the scope always wins:
+//   - all scope filters are added, a user filter with the same field and operator as a scope filter is dropped
+//     and reported as a conflict when its value differs;
+//   - scope sort keys are appended after the user ones, unless the user query has a cursor:
+//     it holds a value per user sort key and would no longer match the sort;
+//   - scope search replaces the user one, scope select narrows the user one and never widens it to all fields;
+//   - scope limit is the maximum page size, a user limit of 0 or above it is clamped.
+func (q Query) Scope(scope Query) (Query, []Conflict) {
+	out := q.Copy()
+	conflicts := []Conflict{}
+
+	filter := make(Filter, 0, len(out.Filter)+len(scope.Filter))
+	for _, f := range out.Filter {
+		s, ok := scopeFilter(scope.Filter, f)
+		if !ok {
+			filter = append(filter, f)
+			continue
+		}
+		if !reflect.DeepEqual(s.Value, f.Value) {
+			conflicts = append(conflicts, Conflict{Scope: s, Dropped: f})
+		}
+	}
+	out.Filter = append(filter, scope.Filter...)
+
+	out.Sort = cursorSort(out, scope.Sort)
+
+	if scope.Search != "" {
+		out.Search = scope.Search
+	}
+
+	if len(scope.Select) > 0 {
+		out.Select = narrowSelect(out.Select, scope.Select)
+	}
+
+	if scope.Pagination.Limit != 0 && (out.Pagination.Limit == 0 || out.Pagination.Limit > scope.Pagination.Limit) {
+		out.Pagination.Limit = scope.Pagination.Limit
+	}
+
+	return out, conflicts
+}
+
+// narrowSelect keeps the selected fields allowed by scope, a parent path covers its children:
+// a field under an allowed parent is kept, a parent of allowed fields is narrowed to them.
+// An empty result selects scope, empty select means all fields.
+func narrowSelect(sel, scope Fields) Fields {
+	out := Fields{}
+	for _, f := range sel {
+		if slices.ContainsFunc(scope, func(s string) bool { return coversPath(s, f) }) {
+			out = append(out, f)
+			continue
+		}
+		for _, s := range scope {
+			if coversPath(f, s) {
+				out = append(out, s)
+			}
+		}
+	}
+	if len(out) == 0 {
+		return slices.Clone(scope)
+	}
+	return SliceUnique(out)
+}
+
+// coversPath reports whether path is parent or nested in it.
+func coversPath(parent, path string) bool {
+	return path == parent || strings.HasPrefix(path, parent+".")
+}
+
+func scopeFilter(scope Filter, f FieldFilter) (FieldFilter, bool) {
+	if isGroupOperator(f.Op) || f.Op == OperatorElemMatch {
+		return FieldFilter{}, false //nolint:exhaustruct
+	}
+	for _, s := range scope {
+		if s.Field == f.Field && equalOperators(s.Op, f.Op) {
+			return s, true
+		}
+	}
+	return FieldFilter{}, false //nolint:exhaustruct
+}
+
+func equalOperators(a, b Operator) bool {
+	if a == OperatorDefault {
+		a = OperatorEqual
+	}
+	if b == OperatorDefault {
+		b = OperatorEqual
+	}
+	return a == b
+}
+
+// cursorSort appends sort keys of o to the sort of q, keeping it as is when q
+// continues after a cursor made for that sort.
+func cursorSort(q Query, o Sort) Sort {
+	if len(q.Pagination.After) > 0 {
+		return q.Sort.Copy()
+	}
+	return mergeSort(q.Sort, o)
+}
+
+func mergeSort(s, o Sort) Sort {
+	out := s.Copy()
+	for _, f := range o {
+		if _, ok := out.Get(f.Key); !ok {
+			out = append(out, f)
+		}
+	}
+	return out
+}
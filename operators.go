@@ -12,11 +12,47 @@ const (
 	OperatorLess           Operator = "lt"
 	OperatorLessOrEqual    Operator = "lte"
 	OperatorSubString      Operator = "substr"
+	OperatorNotIn          Operator = "nin"
+	OperatorPrefix         Operator = "prefix"
+	OperatorSuffix         Operator = "suffix"
+	OperatorRegex          Operator = "regex" // case-sensitive regular expression
+	OperatorBetween        Operator = "between"
+	OperatorAll            Operator = "all"  // slice contains all of the values
+	OperatorSize           Operator = "size" // slice length
+	// OperatorIsNull and OperatorExists take a bool value for any field type.
+	OperatorIsNull Operator = "isnull"
+	OperatorExists Operator = "exists"
 )
 
+// Group operators combine nested filters, see And, Or and Not.
+const (
+	OperatorAnd Operator = "and"
+	OperatorOr  Operator = "or"
+	OperatorNot Operator = "not"
+)
+
+// OperatorElemMatch matches nested filters on a single element of a slice field, see ElemMatch.
+const OperatorElemMatch Operator = "elemMatch"
+
 func isOperator(op Operator) bool {
+	if isBuiltinOperator(op) {
+		return true
+	}
+	_, ok := operators.Load(op)
+	return ok
+}
+
+func isBuiltinOperator(op Operator) bool {
 	return op == OperatorDefault ||
 		op == OperatorEqual || op == OperatorIn || op == OperatorNotEqual ||
 		op == OperatorGreater || op == OperatorGreaterOrEqual ||
-		op == OperatorLess || op == OperatorLessOrEqual || op == OperatorSubString
+		op == OperatorLess || op == OperatorLessOrEqual || op == OperatorSubString ||
+		op == OperatorNotIn || op == OperatorPrefix || op == OperatorSuffix ||
+		op == OperatorRegex || op == OperatorBetween ||
+		op == OperatorIsNull || op == OperatorExists ||
+		op == OperatorAll || op == OperatorSize
+}
+
+func isGroupOperator(op Operator) bool {
+	return op == OperatorAnd || op == OperatorOr || op == OperatorNot
 }
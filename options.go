@@ -0,0 +1,128 @@
+package query
+
+import "time"
+
+// ParamNames are the reserved url parameters ParseQuery reads outside of the filter.
+type ParamNames struct {
+	Search string
+	Sort   string
+	Offset string
+	Limit  string
+	Cursor string
+	Select string
+	// TimeZone is the IANA time zone of dates and calendar units in filter values.
+	TimeZone string
+}
+
+var DefaultParamNames = ParamNames{
+	Search:   "search",
+	Sort:     "sort",
+	Offset:   "offset",
+	Limit:    "limit",
+	Cursor:   "cursor",
+	Select:   "select",
+	TimeZone: "tz",
+}
+
+func (p ParamNames) isReserved(key string) bool {
+	return key == p.Search || key == p.Sort || key == p.Offset || key == p.Limit ||
+		key == p.Cursor || key == p.Select || key == p.TimeZone
+}
+
+type Option func(o *options)
+
+type options struct {
+	params        ParamNames
+	collectErrors bool
+	policy        Policy
+	cursorKey     []byte
+	now           func() time.Time
+	location      *time.Location
+	lazyTime      bool
+	pagination    PaginationPolicy
+	limits        Limits
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		params:        DefaultParamNames,
+		collectErrors: false,
+		policy:        nil,
+		cursorKey:     nil,
+		now:           time.Now,
+		location:      time.UTC,
+		lazyTime:      false,
+		pagination:    DefaultPaginationPolicy,
+		limits:        Limits{MaxFilters: 0, MaxValues: 0, MaxDepth: 0, MaxPattern: 0, MaxSortKeys: 0},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithParamNames overrides the reserved parameter names used by ParseQuery.
+func WithParamNames(p ParamNames) Option {
+	return func(o *options) {
+		o.params = p
+	}
+}
+
+// WithCollectErrors makes parsing continue after an error and return all of them as Errors.
+func WithCollectErrors() Option {
+	return func(o *options) {
+		o.collectErrors = true
+	}
+}
+
+// WithPolicy rejects fields and operators not allowed by the policy.
+func WithPolicy(p Policy) Option {
+	return func(o *options) {
+		o.policy = p
+	}
+}
+
+// WithCursorKey sets the HMAC key cursor tokens are verified with.
+func WithCursorKey(key []byte) Option {
+	return func(o *options) {
+		o.cursorKey = key
+	}
+}
+
+// WithClock sets the current time relative time expressions are resolved with.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// WithLocation sets the time zone of dates and calendar units in time expressions, UTC by default.
+// The TimeZone parameter overrides it in ParseQuery.
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.location = loc
+	}
+}
+
+// WithLazyTime keeps expressions relative to the current time as RelativeTime
+// values instead of resolving them while parsing.
+func WithLazyTime() Option {
+	return func(o *options) {
+		o.lazyTime = true
+	}
+}
+
+// WithPaginationPolicy bounds the pagination of parsed queries instead of DefaultPaginationPolicy.
+func WithPaginationPolicy(p PaginationPolicy) Option {
+	return func(o *options) {
+		o.pagination = p
+	}
+}
+
+// WithLimits rejects queries exceeding the limits with ErrLimitExceeded,
+// both when parsing and in Validate.
+func WithLimits(l Limits) Option {
+	return func(o *options) {
+		o.limits = l
+	}
+}
@@ -0,0 +1,71 @@
+package query
+
+// Page is a page of query results with what is needed to request the next one.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// Total is the number of items matching filter and search, regardless of pagination.
+	Total   uint64 `json:"total"`
+	Offset  uint64 `json:"offset"`
+	Limit   uint64 `json:"limit"`
+	HasNext bool   `json:"has_next"`
+	// Next is the cursor of the last item when there is a next page and the query is sorted.
+	Next Cursor `json:"-"`
+	// NextLink is the url query of the next page, set by WithNextLink.
+	NextLink string `json:"next,omitempty"`
+}
+
+// NewPage builds a page of q from its items, total number of matching items
+// and whether more items follow.
+func NewPage[T any](q Query, items []T, total uint64, hasNext bool) (Page[T], error) {
+	if items == nil {
+		items = []T{}
+	}
+	p := Page[T]{
+		Items:    items,
+		Total:    total,
+		Offset:   q.Pagination.Offset,
+		Limit:    q.Pagination.Limit,
+		HasNext:  hasNext,
+		Next:     nil,
+		NextLink: "",
+	}
+	if hasNext && len(q.Sort) > 0 && len(items) > 0 {
+		c, err := NewCursor(items[len(items)-1], q.Sort)
+		if err != nil {
+			return p, err
+		}
+		p.Next = c
+	}
+	return p, nil
+}
+
+// NextQuery returns q moved to the next page, ok is false when there is none.
+// A query paginated with a cursor continues after Next, otherwise the offset is advanced.
+func (p Page[T]) NextQuery(q Query) (next Query, ok bool) {
+	if !p.HasNext {
+		return q, false
+	}
+	next = q.Copy()
+	if len(q.Pagination.After) > 0 && p.Next != nil {
+		next.Pagination.After = p.Next
+	} else {
+		next.Pagination.Offset += uint64(len(p.Items))
+	}
+	return next, true
+}
+
+// WithNextLink returns the page with NextLink set to the encoded NextQuery of q,
+// options set the parameter names and the cursor key like in Query.Encode.
+func (p Page[T]) WithNextLink(q Query, opts ...Option) (Page[T], error) {
+	next, ok := p.NextQuery(q)
+	if !ok {
+		p.NextLink = ""
+		return p, nil
+	}
+	link, err := next.Encode(opts...)
+	if err != nil {
+		return p, err
+	}
+	p.NextLink = link
+	return p, nil
+}
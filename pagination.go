@@ -0,0 +1,50 @@
+package query
+
+// PaginationPolicy bounds the pagination of queries, zero values disable a bound.
+type PaginationPolicy struct {
+	// DefaultLimit is used when a query has no limit.
+	DefaultLimit uint64
+	// MaxLimit lowers larger limits, queries without a limit get MaxLimit when there is no DefaultLimit.
+	MaxLimit uint64
+	// MaxOffset lowers larger offsets.
+	MaxOffset uint64
+}
+
+// DefaultPaginationPolicy is used by parsers without WithPaginationPolicy,
+// the zero policy leaves queries unbounded.
+var DefaultPaginationPolicy = PaginationPolicy{DefaultLimit: 0, MaxLimit: 0, MaxOffset: 0}
+
+// PaginationClamp reports how a policy changed the requested pagination,
+// e.g. to tell clients the effective limit.
+type PaginationClamp struct {
+	// DefaultLimit is set when the query had no limit and got the default one.
+	DefaultLimit bool
+	// Limit is set when the limit was lowered to the maximum.
+	Limit bool
+	// Offset is set when the offset was lowered to the maximum.
+	Offset bool
+}
+
+// Clamped reports whether the pagination was changed.
+func (c PaginationClamp) Clamped() bool {
+	return c.DefaultLimit || c.Limit || c.Offset
+}
+
+// Normalize applies the policy to the pagination of the query, changes are
+// recorded in Pagination.Clamp.
+func (q Query) Normalize(p PaginationPolicy) Query {
+	pg := &q.Pagination
+	if pg.Limit == 0 && p.DefaultLimit != 0 {
+		pg.Limit = p.DefaultLimit
+		pg.Clamp.DefaultLimit = true
+	}
+	if p.MaxLimit != 0 && (pg.Limit == 0 || pg.Limit > p.MaxLimit) {
+		pg.Limit = p.MaxLimit
+		pg.Clamp.Limit = true
+	}
+	if p.MaxOffset != 0 && pg.Offset > p.MaxOffset {
+		pg.Offset = p.MaxOffset
+		pg.Clamp.Offset = true
+	}
+	return q
+}
@@ -2,8 +2,12 @@ package query
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -17,57 +21,616 @@ type Unmarshaler interface {
 	QueryUnmarshal(v string) (any, error)
 }
 
-func ParseStringFilter[Model any](values map[string]string) (Filter, error) {
+// Marshaler is the inverse of Unmarshaler used to encode a query back to a string.
+type Marshaler interface {
+	QueryMarshal() (string, error)
+}
+
+// ParseQuery builds a complete Query from url parameters. Reserved parameters
+// (see ParamNames) fill search, sort and pagination, the rest are parsed as filter.
+// Pagination is normalized with the pagination policy, see WithPaginationPolicy.
+func ParseQuery[Model any](values url.Values, opts ...Option) (Query, error) {
+	p := newParser[Model](opts)
+	q := p.query(values)
+	return q, p.errs.err()
+}
+
+// ParseSort parses a comma separated list of sort keys, a key prefixed with "-"
+// is sorted in descending order and a ":nullsfirst" or ":nullslast" suffix
+// places nulls, e.g. "-due_date:nullslast,name".
+func ParseSort[Model any](v string, opts ...Option) (Sort, error) {
+	p := newParser[Model](opts)
+	s, _ := p.sort(v)
+	return s, p.errs.err()
+}
+
+func ParseStringFilter[Model any](values map[string]string, opts ...Option) (Filter, error) {
+	multi := make(map[string][]string, len(values))
+	for k, v := range values {
+		multi[k] = []string{v}
+	}
+	return ParseFilter[Model](multi, opts...)
+}
+
+// ParseFilter builds a Filter from multi-valued parameters such as url.Values.
+// Every value of a repeated key becomes its own condition, so "age{gte}=18&age{lte}=65"
+// and "tag=a&tag=b" both produce two filters on the same field. Repeated "in" values are
+// merged into a single list.
+func ParseFilter[Model any](values map[string][]string, opts ...Option) (Filter, error) {
+	p := newParser[Model](opts)
+	f, _ := p.filter(values)
+	return f, p.errs.err()
+}
+
+func modelType[Model any]() reflect.Type {
 	t := genericType[Model]()
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
+	return t
+}
 
-	f := Filter{}
+type parser struct {
+	t    reflect.Type
+	opts *options
+	errs errorCollector
+	// parseValue replaces reflection based value parsing when set
+	parseValue ParseValueFunc
+}
 
-	for k, v := range values {
-		name, op, err := parseMapKey(k)
+func newParser[Model any](opts []Option) *parser {
+	return newTypeParser(modelType[Model](), opts)
+}
+
+func newTypeParser(t reflect.Type, opts []Option) *parser {
+	o := newOptions(opts)
+	return &parser{
+		t:          t,
+		opts:       o,
+		errs:       errorCollector{collect: o.collectErrors, errs: nil},
+		parseValue: nil,
+	}
+}
+
+// Parser methods return false when parsing must stop on an error.
+
+func (p *parser) query(values url.Values) Query {
+	params := p.opts.params
+	q := Query{
+		Search:     values.Get(params.Search),
+		Filter:     Filter{},
+		Sort:       Sort{},
+		Pagination: Pagination{Offset: 0, Limit: 0, After: nil, Clamp: PaginationClamp{}},
+		Select:     nil,
+	}
+	if p.errs.add(p.opts.limits.checkSearch(params.Search, q.Search)) {
+		return q
+	}
+
+	if v := values.Get(params.TimeZone); v != "" {
+		loc, err := time.LoadLocation(v)
 		if err != nil {
-			return nil, err
+			p.errs.add(&FieldError{Err: ErrInvalidValue, Field: params.TimeZone, Op: OperatorDefault, Value: v, Cause: err})
+			return q
 		}
+		p.opts.location = loc
+	}
 
-		t, err := GetTypeByPath(t, name)
+	filterValues := map[string][]string{}
+	for k, v := range values {
+		if !params.isReserved(k) {
+			filterValues[k] = v
+		}
+	}
+	f, ok := p.filter(filterValues)
+	q.Filter = f
+	if !ok {
+		return q
+	}
+
+	for _, v := range values[params.Sort] {
+		s, ok := p.sort(v)
+		for _, f := range s {
+			q.Sort.SetField(f)
+		}
+		if !ok {
+			return q
+		}
+	}
+
+	for _, v := range values[params.Select] {
+		q.Select, ok = p.selectFields(q.Select, v)
+		if !ok {
+			return q
+		}
+	}
+
+	q.Pagination.Offset, ok = p.uint(params.Offset, values.Get(params.Offset))
+	if !ok {
+		return q
+	}
+	q.Pagination.Limit, ok = p.uint(params.Limit, values.Get(params.Limit))
+	if !ok {
+		return q
+	}
+	q = q.Normalize(p.opts.pagination)
+
+	if v := values.Get(params.Cursor); v != "" {
+		c, err := decodeCursor(p.t, v, q.Sort, p.opts.cursorKey)
 		if err != nil {
-			return f, err
+			p.errs.add(&FieldError{Err: ErrInvalidValue, Field: params.Cursor, Op: OperatorDefault, Value: v, Cause: err})
+			return q
 		}
+		q.Pagination.After = c
+	}
 
-		if op == OperatorIn {
-			vals := strings.Split(v, ",")
-			filterValue := reflect.MakeSlice(reflect.SliceOf(t), 0, len(vals))
+	return q
+}
 
-			for _, v := range vals {
-				val, err := parseStringForType(t, v)
-				if err != nil {
-					return f, fmt.Errorf("cant get value for type %s, error: %s", t.Kind().String(), err.Error())
-				}
-				filterValue = reflect.Append(filterValue, reflect.ValueOf(val).Convert(t))
+func (p *parser) uint(name, v string) (uint64, bool) {
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, !p.errs.add(&FieldError{Err: ErrInvalidValue, Field: name, Op: OperatorDefault, Value: v, Cause: err})
+	}
+	return n, true
+}
+
+func (p *parser) selectFields(f Fields, v string) (Fields, bool) {
+	for _, key := range strings.Split(v, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if err := p.opts.limits.checkDepth(key); err != nil {
+			if p.errs.add(err) {
+				return f, false
+			}
+			continue
+		}
+		if _, err := GetTypeByPath(p.t, key); err != nil {
+			if p.errs.add(err) {
+				return f, false
 			}
-			f = append(f, FieldFilter{
-				Field: name,
-				Op:    op,
-				Value: filterValue.Interface(),
-			})
 			continue
 		}
+		f = append(f, key)
+	}
+	return SliceUnique(f), true
+}
 
-		val, err := parseStringForType(t, v)
+func (p *parser) sort(v string) (Sort, bool) {
+	s := Sort{}
+	for _, key := range strings.Split(v, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		f := parseSortKey(key)
+		if err := p.sortField(f.Key); err != nil {
+			if p.errs.add(err) {
+				return s, false
+			}
+			continue
+		}
+		s.SetField(f)
+		if err := p.opts.limits.checkSortKeys(len(s)); err != nil {
+			p.errs.add(err)
+			return s, false
+		}
+	}
+	return s, true
+}
+
+func (p *parser) filter(values map[string][]string) (Filter, bool) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	elems := map[string]map[string][]string{}
+	for _, k := range keys {
+		if field, elemKey, ok := splitElemKey(k); ok {
+			if elems[field] == nil {
+				elems[field] = map[string][]string{}
+			}
+			elems[field][elemKey] = values[k]
+		}
+	}
+
+	f := Filter{}
+	for _, k := range keys {
+		var ok bool
+		if field, _, isElem := splitElemKey(k); isElem {
+			elem, parsed := elems[field]
+			if !parsed {
+				continue
+			}
+			delete(elems, field)
+			f, ok = p.elemMatch(f, field, elem)
+		} else {
+			f, ok = p.fieldFilter(f, k, values[k])
+		}
+		if !ok {
+			return f, false
+		}
+		if err := p.opts.limits.checkFilters(countFilters(f)); err != nil {
+			p.errs.add(err)
+			return f, false
+		}
+	}
+	return f, true
+}
+
+// splitElemKey splits a key of an element condition like "items{elemMatch}.price{gte}" into
+// the slice field and the key relative to its element, "" or "{op}" for the element itself.
+func splitElemKey(key string) (field, elemKey string, ok bool) {
+	marker := "{" + string(OperatorElemMatch) + "}"
+	i := strings.Index(key, marker)
+	if i == -1 {
+		return "", "", false
+	}
+	field, elemKey = key[:i], key[i+len(marker):]
+	switch {
+	case elemKey == "" || strings.HasPrefix(elemKey, "{"):
+		return field, elemKey, true
+	case strings.HasPrefix(elemKey, "."):
+		return field, elemKey[1:], true
+	}
+	return "", "", false
+}
+
+// elemMatch parses conditions on a single element of the slice field, keys are relative to the element.
+func (p *parser) elemMatch(f Filter, field string, values map[string][]string) (Filter, bool) {
+	if _, err := p.sliceType(field); err != nil {
+		return f, !p.errs.add(err)
+	}
+
+	paths := make(map[string][]string, len(values))
+	for k, v := range values {
+		if k == "" || strings.HasPrefix(k, "{") {
+			paths[field+k] = v
+		} else {
+			paths[field+"."+k] = v
+		}
+	}
+	elem, ok := p.filter(paths)
+	if !ok {
+		return f, false
+	}
+	elem = elem.mapFields(func(path string) string {
+		if path == field {
+			return ""
+		}
+		return strings.TrimPrefix(path, field+".")
+	})
+	if err := checkElemConditions(field, elem); err != nil {
+		return f, !p.errs.add(err)
+	}
+	return append(f, ElemMatch(field, elem)), true
+}
+
+func (p *parser) fieldFilter(f Filter, key string, values []string) (Filter, bool) {
+	name, op, err := parseMapKey(key)
+	if err != nil {
+		return f, !p.errs.add(err)
+	}
+
+	if isListOperator(op) {
+		vals := []string{}
+		for _, v := range values {
+			vals = append(vals, splitList(v)...)
+		}
+		if err := p.opts.limits.checkValues(name, op, len(vals)); err != nil {
+			return f, !p.errs.add(err)
+		}
+		filterValue, err := p.fieldValue(name, op, vals)
 		if err != nil {
-			return f, fmt.Errorf("cant get value for type %s, error: %s", t.Kind().String(), err.Error())
+			return f, !p.errs.add(err)
+		}
+		return append(f, FieldFilter{
+			Field: name,
+			Op:    op,
+			Value: filterValue,
+		}), true
+	}
+
+	for _, v := range values {
+		val, err := p.fieldValue(name, op, []string{v})
+		if err != nil {
+			if p.errs.add(err) {
+				return f, false
+			}
+			continue
 		}
 		f = append(f, FieldFilter{
 			Field: name,
 			Op:    op,
 			Value: val,
 		})
+	}
+	return f, true
+}
 
+// fieldValue parses values of a filter, a list for "in" and a single value otherwise.
+func (p *parser) fieldValue(name string, op Operator, values []string) (any, error) {
+	if err := p.checkFilter(name, op); err != nil {
+		return nil, err
 	}
+	for _, v := range values {
+		if err := p.opts.limits.checkPattern(name, op, v); err != nil {
+			return nil, err
+		}
+	}
+	ref, values, isRef := splitFieldRef(op, values)
+	if isRef {
+		return p.fieldRef(name, op, ref)
+	}
+
+	var val any
+	var err error
+	if p.parseValue != nil {
+		val, err = p.parseValue(name, op, values)
+	}
+	if p.parseValue == nil || errors.Is(err, ErrReflectValue) {
+		val, err = p.reflectValue(name, op, values)
+	}
+
+	var fieldErr *FieldError
+	if err != nil && !errors.As(err, &fieldErr) {
+		return nil, &FieldError{Err: ErrInvalidValue, Field: name, Op: op, Value: strings.Join(values, ","), Cause: err}
+	}
+	return val, err
+}
+
+func (p *parser) reflectValue(name string, op Operator, values []string) (any, error) {
+	t, err := p.fieldType(name, op)
+	if err != nil {
+		return nil, err
+	}
+	return p.parseValues(t, op, values)
+}
+
+func (p *parser) parseValues(t reflect.Type, op Operator, values []string) (any, error) {
+	if def, ok := LookupOperator(op); ok && def.Parse != nil {
+		return def.Parse(elemType(t), values)
+	}
+	if val, ok, err := parseOperatorValues(op, values); ok || err != nil {
+		return val, err
+	}
+	if elemType(t) == reflect.TypeOf(time.Time{}) {
+		return p.timeValues(op, values)
+	}
+	if isListOperator(op) {
+		return parseSliceForType(t, values)
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("expected one value, got %d", len(values))
+	}
+	return parseStringForType(t, values[0])
+}
+
+// timeValues parses date-only values, timestamps and time expressions, see TimeRange and RelativeTime.
+func (p *parser) timeValues(op Operator, values []string) (any, error) {
+	if ranges, ok, err := p.timeRanges(op, values); ok || err != nil {
+		return ranges, err
+	}
+	if !isListOperator(op) && len(values) != 1 {
+		return nil, fmt.Errorf("expected one value, got %d", len(values))
+	}
+
+	now := p.opts.now()
+	times := make([]time.Time, 0, len(values))
+	lazy := make([]RelativeTime, 0, len(values))
+	relative := false
+	for _, v := range values {
+		t, rel, err := parseTimeExpr(v, now, p.opts.location)
+		if err != nil {
+			return nil, err
+		}
+		times = append(times, t)
+		lazy = append(lazy, RelativeTime{Expr: v, Location: p.opts.location})
+		relative = relative || rel
+	}
+
+	switch {
+	case p.opts.lazyTime && relative && isListOperator(op):
+		return lazy, nil
+	case p.opts.lazyTime && relative:
+		return lazy[0], nil
+	case isListOperator(op):
+		return times, nil
+	default:
+		return times[0], nil
+	}
+}
+
+// checkFilter checks the operator, the path depth and the policy, it doesn't need the model type.
+func (p *parser) checkFilter(name string, op Operator) error {
+	if !isOperator(op) {
+		return &FieldError{Err: ErrUnknownOperator, Field: name, Op: op, Value: "", Cause: nil}
+	}
+	if err := p.opts.limits.checkDepth(name); err != nil {
+		return err
+	}
+	if p.opts.policy != nil {
+		fp, ok := p.opts.policy.FieldPolicy(name)
+		if !ok || !fp.Filter {
+			return &FieldError{Err: ErrFieldNotAllowed, Field: name, Op: op, Value: "", Cause: nil}
+		}
+		if !fp.allowOperator(op) {
+			return &FieldError{Err: ErrOperatorNotAllowed, Field: name, Op: op, Value: "", Cause: nil}
+		}
+	}
+	return nil
+}
+
+// fieldType checks that the field can be filtered with op and returns its type.
+func (p *parser) fieldType(name string, op Operator) (reflect.Type, error) {
+	if err := p.checkFilter(name, op); err != nil {
+		return nil, err
+	}
+
+	t, err := GetTypeByPath(p.t, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !operatorAllowed(op, t) {
+		return nil, operatorNotAllowed(name, op, t.String())
+	}
+	return t, nil
+}
+
+func operatorNotAllowed(name string, op Operator, typ string) error {
+	return &FieldError{
+		Err: ErrOperatorNotAllowed, Field: name, Op: op, Value: "",
+		Cause: fmt.Errorf("not supported for type %s", typ),
+	}
+}
+
+func (p *parser) sortField(key string) error {
+	if err := p.opts.limits.checkDepth(key); err != nil {
+		return err
+	}
+	if p.opts.policy != nil {
+		fp, ok := p.opts.policy.FieldPolicy(key)
+		if !ok || !fp.Sort {
+			return &FieldError{Err: ErrSortNotAllowed, Field: key, Op: OperatorDefault, Value: "", Cause: nil}
+		}
+	}
+	_, err := GetTypeByPath(p.t, key)
+	return err
+}
+
+// operatorAllowed reports whether op can be applied to a field of type t.
+func operatorAllowed(op Operator, t reflect.Type) bool {
+	switch op {
+	case OperatorAll, OperatorSize, OperatorElemMatch:
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		return t.Kind() == reflect.Slice || t.Kind() == reflect.Array
+	}
+	t = elemType(t)
+	if def, ok := LookupOperator(op); ok {
+		return def.Allowed == nil || def.Allowed(t)
+	}
+	switch {
+	case t.Kind() == reflect.String:
+		return operatorAllowedKind(op, kindString)
+	case IsNumber(t):
+		return operatorAllowedKind(op, kindNumber)
+	case t == reflect.TypeOf(time.Time{}):
+		return operatorAllowedKind(op, kindTime)
+	default:
+		return operatorAllowedKind(op, kindOther)
+	}
+}
+
+type valueKind int
+
+const (
+	kindOther valueKind = iota // only compared for equality
+	kindTime
+	kindNumber
+	kindString
+)
+
+func operatorAllowedKind(op Operator, kind valueKind) bool {
+	switch op {
+	case OperatorDefault, OperatorEqual, OperatorNotEqual, OperatorIn, OperatorNotIn,
+		OperatorIsNull, OperatorExists:
+		return true
+	case OperatorGreater, OperatorGreaterOrEqual, OperatorLess, OperatorLessOrEqual, OperatorBetween:
+		return kind != kindOther
+	case OperatorSubString:
+		return kind == kindNumber || kind == kindString
+	case OperatorPrefix, OperatorSuffix, OperatorRegex:
+		return kind == kindString
+	}
+	return false
+}
+
+// parseOperatorValues parses values of operators that don't take a field value.
+// ok is false when values are parsed as field values.
+func parseOperatorValues(op Operator, values []string) (val any, ok bool, err error) {
+	switch op {
+	case OperatorIsNull, OperatorExists:
+		if len(values) != 1 {
+			return nil, true, fmt.Errorf("expected one value, got %d", len(values))
+		}
+		b, err := parseStringForType(reflect.TypeOf(true), values[0])
+		return b, true, err
+	case OperatorRegex:
+		if len(values) != 1 {
+			return nil, true, fmt.Errorf("expected one value, got %d", len(values))
+		}
+		if _, err := regexp.Compile(values[0]); err != nil {
+			return nil, true, err
+		}
+		return values[0], true, nil
+	case OperatorBetween:
+		if len(values) != 2 {
+			return nil, true, fmt.Errorf("expected two values, got %d", len(values))
+		}
+	case OperatorSize:
+		if len(values) != 1 {
+			return nil, true, fmt.Errorf("expected one value, got %d", len(values))
+		}
+		n, err := strconv.ParseUint(values[0], 10, 31)
+		return int(n), true, err
+	}
+	return nil, false, nil
+}
+
+// splitList splits a comma separated list, `\,` is a literal comma and `\\` a literal backslash.
+func splitList(v string) []string {
+	if !strings.Contains(v, "\\") {
+		return strings.Split(v, ",")
+	}
+
+	out := []string{}
+	cur := strings.Builder{}
+	for i := 0; i < len(v); i++ {
+		switch {
+		case v[i] == '\\' && i+1 < len(v) && (v[i+1] == ',' || v[i+1] == '\\'):
+			i++
+			cur.WriteByte(v[i])
+		case v[i] == ',':
+			out = append(out, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(v[i])
+		}
+	}
+	return append(out, cur.String())
+}
+
+func parseSliceForType(t reflect.Type, vals []string) (any, error) {
+	t = elemType(t)
+	filterValue := reflect.MakeSlice(reflect.SliceOf(t), 0, len(vals))
+
+	for _, v := range vals {
+		val, err := parseStringForType(t, v)
+		if err != nil {
+			return nil, err
+		}
+		filterValue = reflect.Append(filterValue, reflect.ValueOf(val).Convert(t))
+	}
+	return filterValue.Interface(), nil
+}
 
-	return f, nil
+// elemType unwraps slices and pointers down to the type of a single value.
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
 }
 
 func parseMapKey(key string) (name string, operator Operator, err error) {
@@ -77,9 +640,12 @@ func parseMapKey(key string) (name string, operator Operator, err error) {
 		return key, "", nil
 	}
 	name = key[:opStart]
+	if !strings.HasSuffix(key, "}") {
+		return "", "", &FieldError{Err: ErrUnknownOperator, Field: name, Op: Operator(key[opStart:]), Value: "", Cause: nil}
+	}
 	operator = Operator(key[opStart+1 : len(key)-1])
 	if !isOperator(operator) {
-		return "", "", fmt.Errorf("unknow operator: %s", operator)
+		return "", "", &FieldError{Err: ErrUnknownOperator, Field: name, Op: operator, Value: "", Cause: nil}
 	}
 	return name, operator, nil
 }
@@ -87,10 +653,9 @@ func parseMapKey(key string) (name string, operator Operator, err error) {
 var pathTypesCache syncmap[reflect.Type, *syncmap[string, reflect.Type]]
 
 func GetTypeByPath(t reflect.Type, path string) (reflect.Type, error) {
-	if typeCache, ok := pathTypesCache.Load(t); ok && typeCache != nil {
-		if cached, ok := typeCache.Load(path); ok {
-			return cached, nil
-		}
+	typeCache, _ := pathTypesCache.LoadOrStore(t, &syncmap[string, reflect.Type]{}) //nolint:exhaustruct
+	if cached, ok := typeCache.Load(path); ok {
+		return cached, nil
 	}
 
 	parts := strings.Split(path, ".")
@@ -100,7 +665,7 @@ func GetTypeByPath(t reflect.Type, path string) (reflect.Type, error) {
 		case reflect.Struct:
 			f, found := getFieldByJsonTag(t, parts[i])
 			if !found {
-				return nil, fmt.Errorf("invalid path part: %s", parts[i])
+				return nil, unknownPathPart(path, parts[i])
 			}
 			t = f.Type
 			i++
@@ -112,21 +677,27 @@ func GetTypeByPath(t reflect.Type, path string) (reflect.Type, error) {
 		case reflect.Pointer:
 			t = t.Elem()
 		default:
-			return nil, fmt.Errorf("invalid path part: %s", parts[i])
+			return nil, unknownPathPart(path, parts[i])
 		}
 	}
 
-	typeCache, _ := pathTypesCache.LoadOrStore(t, &syncmap[string, reflect.Type]{}) //nolint:exhaustruct
 	typeCache.Store(path, t)
 
 	return t, nil
 }
 
+func unknownPathPart(path, part string) error {
+	return &FieldError{
+		Err: ErrUnknownField, Field: path, Op: OperatorDefault, Value: "",
+		Cause: fmt.Errorf("invalid path part: %s", part),
+	}
+}
+
 func getFieldByJsonTag(typ reflect.Type, name string) (field reflect.StructField, ok bool) {
 	for i := 0; i < typ.NumField(); i++ {
 		field := typ.Field(i)
 
-		key, _ := strings.CutPrefix(field.Tag.Get("json"), ",")
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
 		if key == name {
 			return field, true
 		}
@@ -156,17 +727,31 @@ func parseStringForType(t reflect.Type, v string) (any, error) {
 	case reflect.Slice, reflect.Array, reflect.Pointer:
 		return parseStringForType(t.Elem(), v)
 	case reflect.String:
-		return v, nil
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return strconv.Atoi(v)
+		return reflect.ValueOf(v).Convert(t).Interface(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(v, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(n).Convert(t).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(v, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(n).Convert(t).Interface(), nil
 	case reflect.Float32, reflect.Float64:
-		return strconv.ParseFloat(v, 64)
+		n, err := strconv.ParseFloat(v, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(n).Convert(t).Interface(), nil
 	case reflect.Bool:
 		switch v {
 		case "true", "True":
-			return true, nil
+			return reflect.ValueOf(true).Convert(t).Interface(), nil
 		case "false", "False":
-			return false, nil
+			return reflect.ValueOf(false).Convert(t).Interface(), nil
 		}
 		return nil, fmt.Errorf("unknow bool value: %s", v)
 	case reflect.Struct:
@@ -192,7 +777,7 @@ func parseStringForType(t reflect.Type, v string) (any, error) {
 			if err != nil {
 				return nil, fmt.Errorf("json.Unmarshal error: %s", err.Error())
 			}
-			return val, nil
+			return reflect.ValueOf(val).Elem().Interface(), nil
 		}
 	}
 	return nil, fmt.Errorf("unsupported type: %s", t.String())
@@ -0,0 +1,121 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// ParseValueFunc parses values of a filter without reflection, values holds
+// a single element unless op takes a list. Used by code generated with querygen.
+type ParseValueFunc func(field string, op Operator, values []string) (any, error)
+
+// ParseFilterFunc is ParseFilter with values parsed by fn instead of reflection,
+// the model type is only inspected for field references and ErrReflectValue.
+func ParseFilterFunc[Model any](values map[string][]string, fn ParseValueFunc, opts ...Option) (Filter, error) {
+	p := newParser[Model](opts)
+	p.parseValue = fn
+	f, _ := p.filter(values)
+	return f, p.errs.err()
+}
+
+// ErrReflectValue is returned by a ParseValueFunc to parse the value with
+// reflection, as ParseFilter does with the same options.
+var ErrReflectValue = errors.New("parse value with reflection")
+
+// ParseSliceValue parses values of a slice field, elem parses its elements.
+func ParseSliceValue(field string, op Operator, values []string, elem ParseValueFunc) (any, error) {
+	switch op {
+	case OperatorSize:
+		val, _, err := parseOperatorValues(op, values)
+		return val, err
+	case OperatorAll:
+		return elem(field, OperatorIn, values)
+	}
+	return elem(field, op, values)
+}
+
+// ParseIntValue parses values of an integer field.
+func ParseIntValue[T ~int | ~int8 | ~int16 | ~int32 | ~int64](field string, op Operator, values []string) (any, error) {
+	var zero T
+	bits := reflect.TypeOf(zero).Bits()
+	return parseValues(field, op, values, kindNumber, func(v string) (T, error) {
+		n, err := strconv.ParseInt(v, 10, bits)
+		return T(n), err
+	})
+}
+
+// ParseUintValue parses values of an unsigned integer field.
+func ParseUintValue[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](field string, op Operator, values []string) (any, error) {
+	var zero T
+	bits := reflect.TypeOf(zero).Bits()
+	return parseValues(field, op, values, kindNumber, func(v string) (T, error) {
+		n, err := strconv.ParseUint(v, 10, bits)
+		return T(n), err
+	})
+}
+
+// ParseFloatValue parses values of a float field.
+func ParseFloatValue[T ~float32 | ~float64](field string, op Operator, values []string) (any, error) {
+	var zero T
+	bits := reflect.TypeOf(zero).Bits()
+	return parseValues(field, op, values, kindNumber, func(v string) (T, error) {
+		n, err := strconv.ParseFloat(v, bits)
+		return T(n), err
+	})
+}
+
+// ParseStringValue parses values of a string field.
+func ParseStringValue[T ~string](field string, op Operator, values []string) (any, error) {
+	return parseValues(field, op, values, kindString, func(v string) (T, error) {
+		return T(v), nil
+	})
+}
+
+// ParseBoolValue parses values of a bool field.
+func ParseBoolValue[T ~bool](field string, op Operator, values []string) (any, error) {
+	return parseValues(field, op, values, kindOther, func(v string) (T, error) {
+		switch v {
+		case "true", "True":
+			return true, nil
+		case "false", "False":
+			return false, nil
+		}
+		return false, fmt.Errorf("unknow bool value: %s", v)
+	})
+}
+
+func parseValues[T any](field string, op Operator, values []string, kind valueKind, parse func(string) (T, error)) (any, error) {
+	var zero T
+	if def, ok := LookupOperator(op); ok {
+		t := reflect.TypeOf(zero)
+		if def.Allowed != nil && !def.Allowed(t) {
+			return nil, operatorNotAllowed(field, op, t.String())
+		}
+		if def.Parse != nil {
+			return def.Parse(t, values)
+		}
+	} else if !operatorAllowedKind(op, kind) {
+		return nil, operatorNotAllowed(field, op, fmt.Sprintf("%T", zero))
+	}
+
+	if val, ok, err := parseOperatorValues(op, values); ok || err != nil {
+		return val, err
+	}
+	if isListOperator(op) {
+		vals := make([]T, 0, len(values))
+		for _, v := range values {
+			val, err := parse(v)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, val)
+		}
+		return vals, nil
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("expected one value, got %d", len(values))
+	}
+	return parse(values[0])
+}
@@ -0,0 +1,95 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Policy restricts which fields of a model can be filtered and sorted.
+// Fields unknown to the policy are rejected.
+type Policy interface {
+	FieldPolicy(field string) (FieldPolicy, bool)
+}
+
+type FieldPolicy struct {
+	Filter bool
+	// Operators allowed in filter, empty allows all operators
+	Operators []Operator
+	Sort      bool
+}
+
+func (p FieldPolicy) allowOperator(op Operator) bool {
+	if len(p.Operators) == 0 {
+		return true
+	}
+	for _, o := range p.Operators {
+		if o == op || (o == OperatorEqual && op == OperatorDefault) {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicyMap is an explicit Policy keyed by field path.
+type PolicyMap map[string]FieldPolicy
+
+func (p PolicyMap) FieldPolicy(field string) (FieldPolicy, bool) {
+	f, ok := p[field]
+	return f, ok
+}
+
+// TagPolicy builds a policy from `query` struct tags of the model, e.g.
+//
+//	Name string `json:"name" query:"filter=eq,in;sort"`
+//
+// "filter" without operators allows all of them, "-" excludes the field and everything nested in it.
+// Fields without the tag can't be filtered or sorted.
+func TagPolicy[Model any]() (PolicyMap, error) {
+	p := PolicyMap{}
+	err := walkFields(modelType[Model](), func(path string, field reflect.StructField) (bool, error) {
+		tag, tagged := field.Tag.Lookup("query")
+		if tag == "-" {
+			return false, nil
+		}
+		if tagged {
+			fp, err := parsePolicyTag(tag)
+			if err != nil {
+				return false, fmt.Errorf("field %s: %w", path, err)
+			}
+			p[path] = fp
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func parsePolicyTag(tag string) (FieldPolicy, error) {
+	fp := FieldPolicy{Filter: false, Operators: nil, Sort: false}
+	for _, item := range strings.Split(tag, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+		switch key {
+		case "filter":
+			fp.Filter = true
+			if value == "" {
+				continue
+			}
+			for _, op := range strings.Split(value, ",") {
+				op := Operator(strings.TrimSpace(op))
+				if !isOperator(op) {
+					return fp, fmt.Errorf("unknow operator in query tag: %s", op)
+				}
+				fp.Operators = append(fp.Operators, op)
+			}
+		case "sort":
+			fp.Sort = true
+		case "":
+		default:
+			return fp, fmt.Errorf("unknown query tag option: %s", key)
+		}
+	}
+	return fp, nil
+}
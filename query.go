@@ -7,24 +7,46 @@ type Query struct {
 	Filter     Filter
 	Sort       Sort
 	Pagination Pagination
+	// Select lists fields the result should contain, empty selects everything.
+	Select Fields
 }
 
 func (q Query) Copy() Query {
 	return Query{
-		Search:     q.Search,
-		Filter:     slices.Clone(q.Filter),
-		Sort:       q.Sort.Copy(),
-		Pagination: q.Pagination,
+		Search: q.Search,
+		Filter: slices.Clone(q.Filter),
+		Sort:   q.Sort.Copy(),
+		Pagination: Pagination{
+			Offset: q.Pagination.Offset,
+			Limit:  q.Pagination.Limit,
+			After:  slices.Clone(q.Pagination.After),
+			Clamp:  q.Pagination.Clamp,
+		},
+		Select: slices.Clone(q.Select),
 	}
 }
 
 func (q Query) Fields() Fields {
 	f := append(q.Filter.Fields(), q.Sort.Fields()...)
+	f = append(f, q.Select...)
 	f = SliceUnique(f)
 	return f
 }
 
+// SelectFields returns fields results are projected to: Select plus sort keys,
+// which are kept so the next cursor can be read from the last item. Empty means all fields.
+func (q Query) SelectFields() Fields {
+	if len(q.Select) == 0 {
+		return nil
+	}
+	return SliceUnique(append(slices.Clone(q.Select), q.Sort.Fields()...))
+}
+
 type Pagination struct {
 	Offset uint64
 	Limit  uint64
+	// After switches to keyset pagination, only items sorted after the cursor are returned.
+	After Cursor
+	// Clamp reports how Normalize changed the requested pagination, it isn't encoded.
+	Clamp PaginationClamp
 }
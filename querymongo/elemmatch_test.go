@@ -0,0 +1,62 @@
+package querymongo_test
+
+import (
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/royalcat/query/querymongo"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type elemItem struct {
+	Price int `json:"price"`
+}
+
+type elemModel struct {
+	Items  []elemItem `json:"items"`
+	Scores []int      `json:"scores"`
+}
+
+func TestFilterElemMatch(t *testing.T) {
+	require := require.New(t)
+
+	d, err := querymongo.Filter[elemModel](query.Filter{
+		query.ElemMatch("scores", query.Filter{
+			{Field: "", Op: query.OperatorGreaterOrEqual, Value: 80},
+			{Field: "", Op: query.OperatorLess, Value: 85},
+		}),
+		query.ElemMatch("items", query.Filter{{Field: "price", Op: query.OperatorGreater, Value: 10}}),
+	})
+	require.NoError(err)
+	require.Equal(bson.D{
+		{Key: "scores", Value: bson.M{"$elemMatch": bson.D{{Key: "$gte", Value: 80}, {Key: "$lt", Value: 85}}}},
+		{Key: "items", Value: bson.M{"$elemMatch": bson.D{{Key: "price", Value: bson.M{"$gt": 10}}}}},
+	}, d)
+}
+
+func TestFilterElemMatchUnsupported(t *testing.T) {
+	require := require.New(t)
+
+	cases := map[string]query.Filter{
+		"substr on number elements": {{Field: "", Op: query.OperatorSubString, Value: 8}},
+		"substr on number fields":   {{Field: "price", Op: query.OperatorSubString, Value: 8}},
+		"field reference":           {{Field: "price", Op: query.OperatorLess, Value: query.FieldRef("price")}},
+		"mixed conditions": {
+			{Field: "", Op: query.OperatorGreaterOrEqual, Value: 80},
+			{Field: "price", Op: query.OperatorLess, Value: 85},
+		},
+		"grouped element conditions": {query.Or(
+			query.Filter{{Field: "", Op: query.OperatorLess, Value: 10}},
+			query.Filter{{Field: "", Op: query.OperatorGreater, Value: 90}},
+		)},
+	}
+	for name, elem := range cases {
+		field := "scores"
+		if elem[0].Field == "price" {
+			field = "items"
+		}
+		_, err := querymongo.Filter[elemModel](query.Filter{query.ElemMatch(field, elem)})
+		require.Error(err, name)
+	}
+}
@@ -0,0 +1,48 @@
+package querymongo_test
+
+import (
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/royalcat/query/querymongo"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type refModel struct {
+	Stock        int   `json:"stock"`
+	ReorderLevel *int  `json:"reorder_level"`
+	Bids         []int `json:"bids"`
+}
+
+func TestFilterFieldRef(t *testing.T) {
+	require := require.New(t)
+
+	notNull := func(v string) bson.M {
+		return bson.M{"$ne": bson.A{bson.M{"$ifNull": bson.A{v, nil}}, nil}}
+	}
+
+	d, err := querymongo.Filter[refModel](query.Filter{
+		{Field: "stock", Op: query.OperatorLess, Value: query.FieldRef("reorder_level")},
+	})
+	require.NoError(err)
+	require.Equal(bson.D{{Key: "$expr", Value: bson.M{"$and": bson.A{
+		notNull("$stock"),
+		notNull("$reorder_level"),
+		bson.M{"$lt": bson.A{"$stock", "$reorder_level"}},
+	}}}}, d)
+
+	d, err = querymongo.Filter[refModel](query.Filter{
+		{Field: "bids", Op: query.OperatorGreater, Value: query.FieldRef("stock")},
+	})
+	require.NoError(err)
+	require.Equal(bson.D{{Key: "$expr", Value: bson.M{"$anyElementTrue": bson.A{bson.M{"$map": bson.M{
+		"input": bson.M{"$ifNull": bson.A{"$bids", bson.A{}}},
+		"as":    "a",
+		"in": bson.M{"$and": bson.A{
+			notNull("$$a"),
+			notNull("$stock"),
+			bson.M{"$gt": bson.A{"$$a", "$stock"}},
+		}},
+	}}}}}}, d)
+}
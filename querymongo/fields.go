@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/royalcat/query"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func cleanFirstPart(path string) string {
@@ -27,6 +28,27 @@ func cleanProjectPath(s string) string {
 	return strings.Join(parts, ".")
 }
 
+// Projection includes only the selected fields, array indexes are removed from paths.
+// Paths nested in a selected parent are dropped, mongo rejects them as a path collision.
+func Projection(f query.Fields) bson.D {
+	keys := make([]string, 0, len(f))
+	for _, k := range f {
+		keys = append(keys, cleanProjectPath(clearKeyForMongo(k)))
+	}
+	keys = query.SliceUnique(keys)
+
+	d := bson.D{}
+	for _, k := range keys {
+		covered := slices.ContainsFunc(keys, func(parent string) bool {
+			return strings.HasPrefix(k, parent+".")
+		})
+		if !covered {
+			d = append(d, bson.E{Key: k, Value: 1})
+		}
+	}
+	return d
+}
+
 func cleanFilterFirstPart(f query.Fields) query.Fields {
 	for k, v := range f {
 		f[k] = cleanFirstPart(v)
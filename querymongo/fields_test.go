@@ -0,0 +1,26 @@
+package querymongo_test
+
+import (
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/royalcat/query/querymongo"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestProjection(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(bson.D{
+		{Key: "_id", Value: 1},
+		{Key: "nested", Value: 1},
+		{Key: "items.price", Value: 1},
+	}, querymongo.Projection(query.Fields{"id", "nested.based", "nested", "items.0.price", "items.price", "nested.other._id"}))
+
+	q := query.Query{ //nolint:exhaustruct
+		Select: query.Fields{"nested"},
+		Sort:   query.Sort{{Key: "nested.based", Order: query.ASC}},
+	}
+	require.Equal(bson.D{{Key: "nested", Value: 1}}, querymongo.Projection(q.SelectFields()))
+}
@@ -0,0 +1,35 @@
+package querymongo
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/royalcat/query"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// OperatorFunc translates a filter with a custom operator to a filter element,
+// value is parsed by the query.OperatorDef of the operator.
+type OperatorFunc func(field string, value any) (bson.E, error)
+
+var operators sync.Map // query.Operator -> OperatorFunc
+
+// RegisterOperator sets the translation of a custom operator,
+// the operator must be registered with query.RegisterOperator first.
+func RegisterOperator(op query.Operator, fn OperatorFunc) error {
+	if _, ok := query.LookupOperator(op); !ok {
+		return fmt.Errorf("operator %q is not registered in query", op)
+	}
+	if _, loaded := operators.LoadOrStore(op, fn); loaded {
+		return fmt.Errorf("operator %q is already registered", op)
+	}
+	return nil
+}
+
+func customOperator(op query.Operator) (OperatorFunc, bool) {
+	fn, ok := operators.Load(op)
+	if !ok {
+		return nil, false
+	}
+	return fn.(OperatorFunc), true
+}
@@ -0,0 +1,25 @@
+package querymongo
+
+type Option func(c *config)
+
+type config struct {
+	textIndex bool
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		textIndex: false,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithTextIndex makes Query.Search use $text, the collection must have a text index (see TextIndex).
+// Without it search is a case-insensitive regex over the search fields of the model.
+func WithTextIndex() Option {
+	return func(c *config) {
+		c.textIndex = true
+	}
+}
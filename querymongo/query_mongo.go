@@ -3,7 +3,10 @@ package querymongo
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/royalcat/query"
 	"go.mongodb.org/mongo-driver/bson"
@@ -11,26 +14,97 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func Find[Model any](q query.Query) (bson.D, *options.FindOptions, error) {
-	d, err := Filter[Model](q.Filter)
+// Find translates the query to find arguments, sort keys with a null order
+// need a computed key and are only supported by ToMongoAggIds.
+func Find[Model any](q query.Query, opts ...Option) (bson.D, *options.FindOptions, error) {
+	d, err := match[Model](q, opts)
 	if err != nil {
 		return nil, nil, err
 	}
+	for _, f := range q.Sort {
+		if f.Nulls != query.NullsDefault {
+			return nil, nil, fmt.Errorf("null order of sort key %s is only supported by ToMongoAggIds", f.Key)
+		}
+	}
 
-	opts := options.Find().
+	findOpts := options.Find().
 		SetSkip(int64(q.Pagination.Offset)).
 		SetLimit(int64(q.Pagination.Limit)).
 		SetSort(Sort(q.Sort))
+	if len(q.Select) > 0 {
+		findOpts.SetProjection(Projection(q.SelectFields()))
+	}
+
+	return d, findOpts, nil
+}
+
+// match combines filter, search and cursor of the query.
+func match[Model any](q query.Query, opts []Option) (bson.D, error) {
+	d, err := matchFilter[Model](q, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(q.Pagination.After) > 0 {
+		after, err := afterCursor(q.Sort, q.Pagination.After)
+		if err != nil {
+			return nil, err
+		}
+		d = append(d, after)
+	}
 
-	return d, opts, nil
+	return andDuplicates(d), nil
 }
 
+// matchFilter combines filter and search of the query.
+func matchFilter[Model any](q query.Query, opts []Option) (bson.D, error) {
+	d, err := Filter[Model](q.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Search != "" {
+		s, err := Search[Model](q.Search, opts...)
+		if err != nil {
+			return nil, err
+		}
+		d = append(d, s...)
+	}
+	return d, nil
+}
+
+// Filter translates the filter, relative times are resolved with the current time.
 func Filter[Model any](q query.Filter) (bson.D, error) {
+	q, err := q.Resolve(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return filterDoc(q, reflect.TypeOf((*Model)(nil)).Elem())
+}
+
+func filterDoc(q query.Filter, t reflect.Type) (bson.D, error) {
 	mongoFilter := bson.D{}
 	for _, filter := range q {
+		if group, ok := filter.Group(); ok {
+			e, err := groupOperator(filter.Op, group, t)
+			if err != nil {
+				return nil, err
+			}
+			mongoFilter = append(mongoFilter, e)
+			continue
+		}
+		if elem, ok := filter.Elem(); ok {
+			e, err := elemMatch(filter.Field, elem, t)
+			if err != nil {
+				return nil, err
+			}
+			mongoFilter = append(mongoFilter, e)
+			continue
+		}
+
 		e, err := mongoOperator(
 			filter.Op, filter.Field, filter.Value,
-			reflect.TypeOf((*Model)(nil)).Elem(),
+			t,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("query parsing error: %w", err)
@@ -38,7 +112,64 @@ func Filter[Model any](q query.Filter) (bson.D, error) {
 		mongoFilter = append(mongoFilter, e)
 	}
 
-	return mongoFilter, nil
+	return andDuplicates(mongoFilter), nil
+}
+
+// andDuplicates wraps a filter with repeated keys (like a range on one field) into $and,
+// because a document can't hold the same key twice.
+func andDuplicates(d bson.D) bson.D {
+	keys := make(map[string]bool, len(d))
+	duplicates := false
+	for _, e := range d {
+		if keys[e.Key] {
+			duplicates = true
+			break
+		}
+		keys[e.Key] = true
+	}
+	if !duplicates {
+		return d
+	}
+
+	values := make(bson.A, 0, len(d))
+	for _, e := range d {
+		values = append(values, bson.D{e})
+	}
+	return bson.D{{Key: "$and", Value: values}}
+}
+
+func groupOperator(op query.Operator, group []query.Filter, t reflect.Type) (bson.E, error) {
+	key := ""
+	switch op {
+	case query.OperatorAnd:
+		key = "$and"
+	case query.OperatorOr:
+		key = "$or"
+	case query.OperatorNot:
+		key = "$nor"
+	default:
+		return bson.E{}, fmt.Errorf("unknown group operator: %s", op)
+	}
+
+	if len(group) == 0 {
+		// mongo rejects empty $and/$or/$nor arrays, so use the neutral element of the group
+		switch op {
+		case query.OperatorOr:
+			return bson.E{Key: "$expr", Value: false}, nil
+		default:
+			return bson.E{Key: "$expr", Value: true}, nil
+		}
+	}
+
+	values := make(bson.A, 0, len(group))
+	for _, f := range group {
+		d, err := filterDoc(f, t)
+		if err != nil {
+			return bson.E{}, err
+		}
+		values = append(values, d)
+	}
+	return bson.E{Key: key, Value: values}, nil
 }
 
 func mongoOperator(q query.Operator, name string, value any, v reflect.Type) (bson.E, error) {
@@ -46,9 +177,13 @@ func mongoOperator(q query.Operator, name string, value any, v reflect.Type) (bs
 		v = v.Elem()
 	}
 
-	t, err := query.GetTypeByPath(v, name)
-	if err != nil {
-		return bson.E{}, err
+	t := v
+	if name != "" {
+		var err error
+		t, err = query.GetTypeByPath(v, name)
+		if err != nil {
+			return bson.E{}, err
+		}
 	}
 
 	// val, err := query.GetValueForType(t, value)
@@ -61,6 +196,13 @@ func mongoOperator(q query.Operator, name string, value any, v reflect.Type) (bs
 	// 	name = strings.TrimSuffix(name, "id") + "_id"
 	// }
 
+	if fn, ok := customOperator(q); ok {
+		return fn(name, value)
+	}
+	if ref, ok := value.(query.FieldRef); ok {
+		return fieldRefExpr(q, name, ref, v)
+	}
+
 	e := bson.E{
 		Key:   name,
 		Value: value,
@@ -70,7 +212,7 @@ func mongoOperator(q query.Operator, name string, value any, v reflect.Type) (bs
 	case query.OperatorEqual:
 		e.Value = bson.M{"$eq": value}
 	case query.OperatorIn:
-		values, err := interfacesSlice(v)
+		values, err := interfacesSlice(value)
 		if err != nil {
 			return e, err
 		}
@@ -98,16 +240,249 @@ func mongoOperator(q query.Operator, name string, value any, v reflect.Type) (bs
 
 			e.Value = primitive.Regex{Pattern: pattern, Options: "i"}
 		}
+	case query.OperatorNotIn:
+		values, err := interfacesSlice(value)
+		if err != nil {
+			return e, err
+		}
+		e.Value = bson.M{"$nin": values}
+	case query.OperatorBetween:
+		values, err := interfacesSlice(value)
+		if err != nil {
+			return e, err
+		}
+		if len(values) != 2 {
+			return e, fmt.Errorf("between expects two values, got %d", len(values))
+		}
+		e.Value = bson.M{"$gte": values[0], "$lte": values[1]}
+	case query.OperatorPrefix:
+		pattern := stringValue(value)
+		e.Value = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(pattern), Options: ""}
+	case query.OperatorSuffix:
+		pattern := stringValue(value)
+		e.Value = primitive.Regex{Pattern: regexp.QuoteMeta(pattern) + "$", Options: ""}
+	case query.OperatorRegex:
+		e.Value = primitive.Regex{Pattern: stringValue(value), Options: ""}
+	case query.OperatorAll:
+		values, err := interfacesSlice(value)
+		if err != nil {
+			return e, err
+		}
+		e.Value = bson.M{"$all": values}
+	case query.OperatorSize:
+		e.Value = bson.M{"$size": value}
+	case query.OperatorIsNull:
+		if isNull, _ := value.(bool); isNull {
+			e.Value = bson.M{"$eq": nil}
+		} else {
+			e.Value = bson.M{"$ne": nil}
+		}
+	case query.OperatorExists:
+		exists, _ := value.(bool)
+		e.Value = bson.M{"$exists": exists}
 	case query.OperatorDefault:
 		e.Value = value
+	default:
+		return e, fmt.Errorf("operator %s is not supported by querymongo", q)
 	}
 	return e, nil
 }
 
+var exprOperators = map[query.Operator]string{
+	query.OperatorDefault:        "$eq",
+	query.OperatorEqual:          "$eq",
+	query.OperatorNotEqual:       "$ne",
+	query.OperatorGreater:        "$gt",
+	query.OperatorGreaterOrEqual: "$gte",
+	query.OperatorLess:           "$lt",
+	query.OperatorLessOrEqual:    "$lte",
+}
+
+// fieldRefExpr compares two fields of a document with $expr the way queryreflect does:
+// null and missing values never match and arrays match when any element does.
+func fieldRefExpr(q query.Operator, name string, ref query.FieldRef, t reflect.Type) (bson.E, error) {
+	op, ok := exprOperators[q]
+	if !ok {
+		return bson.E{}, fmt.Errorf("operator %s doesn't support field references", q)
+	}
+	expr := anyElementExpr(t, name, "a", func(a string) any {
+		return anyElementExpr(t, string(ref), "b", func(b string) any {
+			return bson.M{"$and": bson.A{
+				bson.M{"$ne": bson.A{bson.M{"$ifNull": bson.A{a, nil}}, nil}},
+				bson.M{"$ne": bson.A{bson.M{"$ifNull": bson.A{b, nil}}, nil}},
+				bson.M{op: bson.A{a, b}},
+			}}
+		})
+	})
+	return bson.E{Key: "$expr", Value: expr}, nil
+}
+
+// anyElementExpr applies cond to the value of path, or to each of its elements
+// when path goes through an array.
+func anyElementExpr(t reflect.Type, path, as string, cond func(v string) any) any {
+	if !isArrayPath(t, path) {
+		return cond("$" + path)
+	}
+	return bson.M{"$anyElementTrue": bson.A{bson.M{"$map": bson.M{
+		"input": bson.M{"$ifNull": bson.A{"$" + path, bson.A{}}},
+		"as":    as,
+		"in":    cond("$$" + as),
+	}}}}
+}
+
+// isArrayPath reports whether the path or any of its parents is a slice.
+func isArrayPath(t reflect.Type, path string) bool {
+	parts := strings.Split(path, ".")
+	for i := range parts {
+		pt, err := query.GetTypeByPath(t, strings.Join(parts[:i+1], "."))
+		if err != nil {
+			return false
+		}
+		for pt.Kind() == reflect.Ptr {
+			pt = pt.Elem()
+		}
+		if pt.Kind() == reflect.Slice || pt.Kind() == reflect.Array {
+			return true
+		}
+	}
+	return false
+}
+
+// elemMatch translates an element filter, conditions on the element itself
+// are merged into a single operator document. Operators evaluated on the whole
+// document ($where, $expr) and custom operators can't be used inside $elemMatch.
+func elemMatch(field string, elem query.Filter, t reflect.Type) (bson.E, error) {
+	if err := checkElemFilter(field, elem, true); err != nil {
+		return bson.E{}, err
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	st, err := query.GetTypeByPath(t, field)
+	if err != nil {
+		return bson.E{}, err
+	}
+	for st.Kind() == reflect.Ptr || st.Kind() == reflect.Slice || st.Kind() == reflect.Array {
+		st = st.Elem()
+	}
+
+	doc := bson.D{}
+	fields := query.Filter{}
+	for _, f := range elem {
+		if _, isGroup := f.Group(); f.Field != "" || isGroup {
+			fields = append(fields, f)
+			continue
+		}
+		e, err := mongoOperator(f.Op, "", f.Value, st)
+		if err != nil {
+			return bson.E{}, fmt.Errorf("query parsing error: %w", err)
+		}
+		if e.Key != "" {
+			return bson.E{}, fmt.Errorf("operator %s on elements of %s is not supported in $elemMatch", f.Op, field)
+		}
+		switch v := e.Value.(type) {
+		case bson.M:
+			for k, op := range v {
+				doc = append(doc, bson.E{Key: k, Value: op})
+			}
+		case primitive.Regex:
+			doc = append(doc, bson.E{Key: "$regex", Value: v})
+		default:
+			doc = append(doc, bson.E{Key: "$eq", Value: v})
+		}
+	}
+	if len(fields) > 0 {
+		d, err := filterDoc(fields, st)
+		if err != nil {
+			return bson.E{}, err
+		}
+		if hasKey(d, "$where", "$expr") {
+			return bson.E{}, fmt.Errorf("filter of %s needs $where or $expr that are not supported in $elemMatch", field)
+		}
+		doc = append(doc, d...)
+	}
+
+	return bson.E{Key: field, Value: bson.M{"$elemMatch": doc}}, nil
+}
+
+// checkElemFilter rejects conditions $elemMatch can't express: custom operators,
+// field references and conditions on the element itself mixed with field conditions or in groups.
+func checkElemFilter(field string, elem query.Filter, top bool) error {
+	self, fields := false, false
+	for _, f := range elem {
+		if group, ok := f.Group(); ok {
+			for _, g := range group {
+				if err := checkElemFilter(field, g, false); err != nil {
+					return err
+				}
+			}
+			fields = true
+			continue
+		}
+		if f.Op == query.OperatorElemMatch {
+			fields = true
+			continue
+		}
+		if _, ok := query.LookupOperator(f.Op); ok {
+			return fmt.Errorf("custom operator %s is not supported in $elemMatch of %s", f.Op, field)
+		}
+		if _, ok := f.Value.(query.FieldRef); ok {
+			return fmt.Errorf("field references are not supported in $elemMatch of %s", field)
+		}
+		if f.Field == "" {
+			self = true
+		} else {
+			fields = true
+		}
+	}
+	switch {
+	case self && !top:
+		return fmt.Errorf("conditions on elements of %s can't be grouped in $elemMatch", field)
+	case self && fields:
+		return fmt.Errorf("conditions on elements of %s can't be mixed with field conditions", field)
+	}
+	return nil
+}
+
+// hasKey reports whether any document nested in v has one of the keys.
+func hasKey(v any, keys ...string) bool {
+	switch v := v.(type) {
+	case bson.D:
+		for _, e := range v {
+			if slices.Contains(keys, e.Key) || hasKey(e.Value, keys...) {
+				return true
+			}
+		}
+	case bson.M:
+		for k, e := range v {
+			if slices.Contains(keys, k) || hasKey(e, keys...) {
+				return true
+			}
+		}
+	case bson.A:
+		for _, e := range v {
+			if hasKey(e, keys...) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stringValue returns the value of a string kind, including named string types.
+func stringValue(v any) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.String {
+		return ""
+	}
+	return rv.String()
+}
+
 func interfacesSlice(v any) ([]any, error) {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() == reflect.Slice {
-		var out []any
+		out := make([]any, 0, rv.Len())
 		for i := 0; i < rv.Len(); i++ {
 			out = append(out, rv.Index(i).Interface())
 		}
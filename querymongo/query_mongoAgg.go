@@ -1,40 +1,25 @@
 package querymongo
 
 import (
+	"slices"
+
 	"github.com/royalcat/query"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func ToMongoAggIds[Model any](q query.Query) (mongo.Pipeline, error) {
-	agg := mongo.Pipeline{
-		bson.D{{Key: "$sort", Value: bson.M{"_id": -1}}},
-	}
+func ToMongoAggIds[Model any](q query.Query, opts ...Option) (mongo.Pipeline, error) {
+	agg := mongo.Pipeline{}
 
-	m, err := Filter[Model](q.Filter)
+	m, err := match[Model](q, opts)
 	if err != nil {
 		return nil, err
 	}
+	// $match goes first, $text search is only allowed in the first stage
 	if len(m) > 0 {
 		agg = append(agg, bson.D{{Key: "$match", Value: m}})
 	}
-
-	s := Sort(q.Sort)
-	if len(s) > 0 {
-		sort := append(
-			mtoD(s),
-			bson.E{Key: "_id", Value: -1},
-		)
-		agg = append(agg, bson.D{{Key: "$sort", Value: sort}})
-	}
-
-	if q.Pagination.Offset != 0 {
-		agg = append(agg, bson.D{{Key: "$skip", Value: q.Pagination.Offset}})
-	}
-
-	if q.Pagination.Limit != 0 {
-		agg = append(agg, bson.D{{Key: "$limit", Value: q.Pagination.Limit}})
-	}
+	agg = append(agg, pageStages(q, q.Pagination.Limit)...)
 
 	// if settings.Config.Debug {
 	// 	aggData, err := bson.MarshalExtJSON(bson.M{"agg": agg}, true, true)
@@ -48,10 +33,99 @@ func ToMongoAggIds[Model any](q query.Query) (mongo.Pipeline, error) {
 	return agg, nil
 }
 
-func mtoD(m bson.M) bson.D {
-	d := bson.D{}
-	for k, v := range m {
-		d = append(d, bson.E{Key: k, Value: v})
+// ToMongoAggPage returns a pipeline producing a single PageResult document with the page
+// and the total number of matching documents, use PageResult.Page to get the query.Page.
+func ToMongoAggPage[Model any](q query.Query, opts ...Option) (mongo.Pipeline, error) {
+	agg := mongo.Pipeline{}
+
+	m, err := matchFilter[Model](q, opts)
+	if err != nil {
+		return nil, err
+	}
+	// $match goes first, $text search is only allowed in the first stage
+	if len(m) > 0 {
+		agg = append(agg, bson.D{{Key: "$match", Value: andDuplicates(m)}})
+	}
+
+	items := mongo.Pipeline{}
+	if len(q.Pagination.After) > 0 {
+		after, err := afterCursor(q.Sort, q.Pagination.After)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, bson.D{{Key: "$match", Value: bson.D{after}}})
+	}
+	// one more item tells whether there is a next page
+	limit := q.Pagination.Limit
+	if limit != 0 {
+		limit++
+	}
+	items = append(items, pageStages(q, limit)...)
+
+	agg = append(agg, bson.D{{Key: "$facet", Value: bson.D{
+		{Key: "items", Value: items},
+		{Key: "total", Value: bson.A{bson.D{{Key: "$count", Value: "total"}}}},
+	}}})
+	return agg, nil
+}
+
+// PageResult is the document produced by the ToMongoAggPage pipeline.
+type PageResult[T any] struct {
+	Items []T         `bson:"items"`
+	Total []pageCount `bson:"total"`
+}
+
+type pageCount struct {
+	Total uint64 `bson:"total"`
+}
+
+// Page converts the result to the page of q.
+func (r PageResult[T]) Page(q query.Query) (query.Page[T], error) {
+	var total uint64
+	if len(r.Total) > 0 {
+		total = r.Total[0].Total
+	}
+	items, hasNext := r.Items, false
+	if q.Pagination.Limit != 0 && uint64(len(items)) > q.Pagination.Limit {
+		items, hasNext = items[:q.Pagination.Limit], true
+	}
+	return query.NewPage(q, items, total, hasNext)
+}
+
+// pageStages sorts, paginates with the given limit and projects the query results.
+func pageStages(q query.Query, limit uint64) mongo.Pipeline {
+	agg := mongo.Pipeline{}
+	agg = append(agg, bson.D{{Key: "$sort", Value: bson.M{"_id": -1}}})
+
+	s, nulls := sortWithNulls(q.Sort)
+	if len(s) > 0 {
+		sort := s
+		if !slices.ContainsFunc(s, func(e bson.E) bool { return e.Key == "_id" }) {
+			sort = append(sort, bson.E{Key: "_id", Value: -1})
+		}
+		if len(nulls) > 0 {
+			agg = append(agg, bson.D{{Key: "$addFields", Value: nulls}})
+		}
+		agg = append(agg, bson.D{{Key: "$sort", Value: sort}})
+		if len(nulls) > 0 {
+			keys := bson.A{}
+			for _, e := range nulls {
+				keys = append(keys, e.Key)
+			}
+			agg = append(agg, bson.D{{Key: "$unset", Value: keys}})
+		}
+	}
+
+	if q.Pagination.Offset != 0 {
+		agg = append(agg, bson.D{{Key: "$skip", Value: q.Pagination.Offset}})
+	}
+
+	if limit != 0 {
+		agg = append(agg, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	if len(q.Select) > 0 {
+		agg = append(agg, bson.D{{Key: "$project", Value: Projection(q.SelectFields())}})
 	}
-	return d
+	return agg
 }
@@ -0,0 +1,66 @@
+package querymongo
+
+import (
+	"regexp"
+
+	"github.com/royalcat/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Search translates Query.Search of the model to filter elements.
+func Search[Model any](search string, opts ...Option) (bson.D, error) {
+	tokens := query.SearchTokens(search)
+	if len(tokens) == 0 {
+		return bson.D{}, nil
+	}
+
+	if newConfig(opts).textIndex {
+		return bson.D{{Key: "$text", Value: bson.M{"$search": search}}}, nil
+	}
+
+	fields, err := query.SearchFields[Model]()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, query.ErrNoSearchFields
+	}
+
+	d := bson.D{}
+	for _, token := range tokens {
+		or := make(bson.A, 0, len(fields))
+		for _, f := range fields {
+			or = append(or, bson.M{
+				cleanProjectPath(clearKeyForMongo(f.Path)): primitive.Regex{Pattern: regexp.QuoteMeta(token), Options: "i"},
+			})
+		}
+		d = append(d, bson.E{Key: "$or", Value: or})
+	}
+	return d, nil
+}
+
+// TextIndex describes the text index over the search fields of the model with their weights.
+func TextIndex[Model any]() (mongo.IndexModel, error) {
+	fields, err := query.SearchFields[Model]()
+	if err != nil {
+		return mongo.IndexModel{}, err
+	}
+	if len(fields) == 0 {
+		return mongo.IndexModel{}, query.ErrNoSearchFields
+	}
+
+	keys := bson.D{}
+	weights := bson.D{}
+	for _, f := range fields {
+		path := cleanProjectPath(clearKeyForMongo(f.Path))
+		keys = append(keys, bson.E{Key: path, Value: "text"})
+		weights = append(weights, bson.E{Key: path, Value: f.Weight})
+	}
+	return mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetWeights(weights),
+	}, nil
+}
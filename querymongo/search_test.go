@@ -0,0 +1,23 @@
+package querymongo_test
+
+import (
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/royalcat/query/querymongo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchNoFields(t *testing.T) {
+	require := require.New(t)
+
+	type model struct {
+		Title string `json:"title"`
+	}
+
+	_, err := querymongo.Search[model]("go")
+	require.ErrorIs(err, query.ErrNoSearchFields)
+
+	_, err = querymongo.TextIndex[model]()
+	require.ErrorIs(err, query.ErrNoSearchFields)
+}
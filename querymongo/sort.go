@@ -7,19 +7,92 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 )
 
-func Sort(s query.Sort) bson.M {
-	m := bson.M{}
+// Sort translates the sort keys, null order needs computed keys, see ToMongoAggIds.
+func Sort(s query.Sort) bson.D {
+	d := bson.D{}
 
 	for _, f := range s {
 		k := clearKeyForMongo(f.Key)
 		switch f.Order {
 		case query.ASC:
-			m[k] = 1
+			d = append(d, bson.E{Key: k, Value: 1})
 		case query.DESC:
-			m[k] = -1
+			d = append(d, bson.E{Key: k, Value: -1})
 		default:
 			panic(fmt.Errorf("unknown sort order: %d", f.Order))
 		}
 	}
-	return m
+	return d
+}
+
+// sortWithNulls returns the sort with a computed key before every key with a null order
+// and the fields computing them, mongo always sorts nulls as the lowest value.
+func sortWithNulls(s query.Sort) (sort bson.D, fields bson.D) {
+	sort, fields = bson.D{}, bson.D{}
+	for i, f := range s {
+		if f.Nulls != query.NullsDefault {
+			key := fmt.Sprintf("_nulls%d", i)
+			isNull := bson.M{"$eq": bson.A{bson.M{"$ifNull": bson.A{"$" + clearKeyForMongo(f.Key), nil}}, nil}}
+			fields = append(fields, bson.E{Key: key, Value: bson.M{"$cond": bson.A{isNull, 1, 0}}})
+
+			order := -1
+			if f.Nulls == query.NullsLast {
+				order = 1
+			}
+			sort = append(sort, bson.E{Key: key, Value: order})
+		}
+		sort = append(sort, Sort(query.Sort{f})...)
+	}
+	return sort, fields
+}
+
+// afterValue matches values of the sort key sorted strictly after v,
+// ok is false when there are none.
+func afterValue(f query.SortField, v any) (e bson.E, ok bool) {
+	k := clearKeyForMongo(f.Key)
+	if v == nil {
+		if f.NullsAfter() {
+			return bson.E{}, false
+		}
+		return bson.E{Key: k, Value: bson.M{"$ne": nil}}, true
+	}
+
+	op := "$gt"
+	if f.Order == query.DESC {
+		op = "$lt"
+	}
+	if !f.NullsAfter() {
+		return bson.E{Key: k, Value: bson.M{op: v}}, true
+	}
+	return bson.E{Key: "$or", Value: bson.A{
+		bson.D{{Key: k, Value: bson.M{op: v}}},
+		bson.D{{Key: k, Value: nil}},
+	}}, true
+}
+
+// afterCursor matches documents sorted strictly after the cursor:
+// (a > va) OR (a == va AND b > vb) OR ...
+func afterCursor(s query.Sort, c query.Cursor) (bson.E, error) {
+	if len(c) != len(s) {
+		return bson.E{}, fmt.Errorf("%w: cursor doesn't match sort", query.ErrInvalidCursor)
+	}
+
+	or := bson.A{}
+	for i, f := range s {
+		cond := bson.D{}
+		for j := 0; j < i; j++ {
+			cond = append(cond, bson.E{Key: clearKeyForMongo(s[j].Key), Value: bson.M{"$eq": c[j]}})
+		}
+		after, ok := afterValue(f, c[i])
+		if !ok {
+			continue
+		}
+		cond = append(cond, after)
+		or = append(or, cond)
+	}
+	if len(or) == 0 {
+		// nothing sorts after the cursor
+		return bson.E{Key: "$expr", Value: false}, nil
+	}
+	return bson.E{Key: "$or", Value: or}, nil
 }
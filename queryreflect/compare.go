@@ -1,8 +1,10 @@
 package queryreflect
 
 import (
+	"cmp"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -12,114 +14,168 @@ import (
 
 func reflectCompare(o query.Operator, v1, v2 reflect.Value) bool {
 	t := v1.Type()
-	switch t.Kind() {
-	case reflect.Bool:
-		if v1.Type().Kind() != v2.Type().Kind() {
-			return false
-		}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
 		switch o {
-		case query.OperatorEqual:
-			return v1.Bool() == v2.Bool()
-		case query.OperatorNotEqual:
-			return v1.Bool() != v2.Bool()
-		}
-	case reflect.Float32, reflect.Float64:
-		if v1.Type().Kind() != v2.Type().Kind() {
-			return false
+		case query.OperatorNotIn:
+			return !reflectCompare(query.OperatorIn, v1, v2)
+		case query.OperatorSize:
+			return v2.CanInt() && int64(v1.Len()) == v2.Int()
+		case query.OperatorAll:
+			if v2.Kind() != reflect.Slice {
+				return false
+			}
+			for i := 0; i < v2.Len(); i++ {
+				if !reflectCompare(query.OperatorEqual, v1, v2.Index(i)) {
+					return false
+				}
+			}
+			return true
 		}
-		switch o {
-		case query.OperatorEqual, query.OperatorDefault:
-			return v1.Float() == v2.Float()
-		case query.OperatorNotEqual:
-			return v1.Float() != v2.Float()
-		case query.OperatorGreater:
-			return v1.Float() > v2.Float()
-		case query.OperatorLess:
-			return v1.Float() < v2.Float()
-		case query.OperatorGreaterOrEqual:
-			return v1.Float() >= v2.Float()
-		case query.OperatorLessOrEqual:
-			return v1.Float() <= v2.Float()
+		for i := 0; i < v1.Len(); i++ {
+			if reflectCompare(o, v1.Index(i), v2) {
+				return true
+			}
 		}
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		if v1.Type().Kind() != v2.Type().Kind() {
+		return false
+	}
+
+	switch o {
+	case query.OperatorIn, query.OperatorNotIn:
+		if v2.Kind() != reflect.Slice {
 			return false
 		}
-		switch o {
-		case query.OperatorEqual, query.OperatorDefault:
-			return v1.Int() == v2.Int()
-		case query.OperatorNotEqual:
-			return v1.Int() != v2.Int()
-		case query.OperatorGreater:
-			return v1.Int() > v2.Int()
-		case query.OperatorLess:
-			return v1.Int() < v2.Int()
-		case query.OperatorGreaterOrEqual:
-			return v1.Int() >= v2.Int()
-		case query.OperatorLessOrEqual:
-			return v1.Int() <= v2.Int()
-		case query.OperatorSubString:
-			return strings.Contains(strconv.Itoa(int(v1.Int())), strconv.Itoa(int(v1.Int())))
+		found := false
+		for i := 0; i < v2.Len() && !found; i++ {
+			found = reflectCompare(query.OperatorEqual, v1, v2.Index(i))
 		}
-	case reflect.String:
+		return found == (o == query.OperatorIn)
+	case query.OperatorBetween:
+		return v2.Kind() == reflect.Slice && v2.Len() == 2 &&
+			reflectCompare(query.OperatorGreaterOrEqual, v1, v2.Index(0)) &&
+			reflectCompare(query.OperatorLessOrEqual, v1, v2.Index(1))
+	case query.OperatorRegex:
+		re, ok := v2.Interface().(*regexp.Regexp)
+		return ok && t.Kind() == reflect.String && re.MatchString(v1.String())
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
 		if v1.Type().Kind() != v2.Type().Kind() {
 			return false
 		}
 		switch o {
 		case query.OperatorEqual, query.OperatorDefault:
-			return v1.String() == v2.String()
+			return v1.Bool() == v2.Bool()
 		case query.OperatorNotEqual:
-			return v1.String() != v2.String()
-		case query.OperatorGreater:
-			return v1.String() > v2.String()
-		case query.OperatorLess:
-			return v1.String() < v2.String()
-		case query.OperatorGreaterOrEqual:
-			return v1.String() >= v2.String()
-		case query.OperatorLessOrEqual:
-			return v1.String() <= v2.String()
-		case query.OperatorSubString:
-			return strings.Contains(v1.String(), v2.String())
-		}
-
-	case reflect.Struct:
-		if v1.Type().Kind() != v2.Type().Kind() {
-			return false
+			return v1.Bool() != v2.Bool()
 		}
-
-		switch t {
-		case reflect.TypeOf(time.Time{}):
-			return reflectCompare(o,
-				reflect.ValueOf(v1.Interface().(time.Time).Unix()),
-				reflect.ValueOf(v2.Interface().(time.Time).Unix()),
-			)
-			// case reflect.TypeOf(types.Date{}):
-			// 	return reflectCompare(o,
-			// 		reflect.ValueOf(v1.Interface().(types.Date).Unix()),
-			// 		reflect.ValueOf(v2.Interface().(types.Date).Unix()),
-			// 	)
-			// case reflect.TypeOf(uuid.UUID{}):
-			// 	return o.reflectCompare(
-			// 		reflect.ValueOf(v1.Interface().(uuid.UUID).String()),
-			// 		reflect.ValueOf(v2.Interface().(uuid.UUID).String()),
-			// 	)
-			// }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if o == query.OperatorSubString && v1.Kind() == v2.Kind() {
+			return strings.Contains(strconv.FormatInt(v1.Int(), 10), strconv.FormatInt(v2.Int(), 10))
 		}
-	case reflect.Array, reflect.Slice:
-		if v1.Type().Elem().Kind() != v2.Type().Kind() {
-			return false
+		return compareOrdered(o, v1, v2)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if o == query.OperatorSubString && v1.Kind() == v2.Kind() {
+			return strings.Contains(strconv.FormatUint(v1.Uint(), 10), strconv.FormatUint(v2.Uint(), 10))
 		}
-		for i := 0; i < v1.Len(); i++ {
-			if reflectCompare(o, v1.Index(i), v2) {
-				return true
+		return compareOrdered(o, v1, v2)
+	case reflect.String:
+		if v1.Kind() == v2.Kind() {
+			switch o {
+			case query.OperatorSubString:
+				return strings.Contains(v1.String(), v2.String())
+			case query.OperatorPrefix:
+				return strings.HasPrefix(v1.String(), v2.String())
+			case query.OperatorSuffix:
+				return strings.HasSuffix(v1.String(), v2.String())
 			}
 		}
+		return compareOrdered(o, v1, v2)
+	case reflect.Float32, reflect.Float64, reflect.Struct:
+		return compareOrdered(o, v1, v2)
 	}
 
 	return false
 }
 
+func compareOrdered(o query.Operator, v1, v2 reflect.Value) bool {
+	c, ok := compareValues(v1, v2)
+	if !ok {
+		return false
+	}
+	res, _ := compareResult(o, c)
+	return res
+}
+
+// compareResult applies a comparison operator to the result of an ordering.
+func compareResult(o query.Operator, c int) (res, ok bool) {
+	switch o {
+	case query.OperatorEqual, query.OperatorDefault:
+		return c == 0, true
+	case query.OperatorNotEqual:
+		return c != 0, true
+	case query.OperatorGreater:
+		return c > 0, true
+	case query.OperatorGreaterOrEqual:
+		return c >= 0, true
+	case query.OperatorLess:
+		return c < 0, true
+	case query.OperatorLessOrEqual:
+		return c <= 0, true
+	}
+	return false, false
+}
+
+// compareValues orders two values of the same kind, ok is false when they can't be compared.
+func compareValues(v1, v2 reflect.Value) (c int, ok bool) {
+	v1, v2 = deref(v1), deref(v2)
+	if !v1.IsValid() || !v2.IsValid() || v1.Kind() != v2.Kind() {
+		return 0, false
+	}
+
+	switch v1.Kind() {
+	case reflect.Bool:
+		switch {
+		case v1.Bool() == v2.Bool():
+			return 0, true
+		case v2.Bool():
+			return -1, true
+		default:
+			return 1, true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(v1.Int(), v2.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(v1.Uint(), v2.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(v1.Float(), v2.Float()), true
+	case reflect.String:
+		return cmp.Compare(v1.String(), v2.String()), true
+	case reflect.Struct:
+		t1, ok1 := v1.Interface().(time.Time)
+		t2, ok2 := v2.Interface().(time.Time)
+		if ok1 && ok2 {
+			return t1.Compare(t2), true
+		}
+	}
+	return 0, false
+}
+
+// deref unwraps pointers and interfaces, nil becomes an invalid value.
+func deref(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
 func getValueByPath(modelValue reflect.Value, path string) ([]reflect.Value, error) {
+	if path == "" {
+		return []reflect.Value{modelValue}, nil
+	}
 	parts := strings.Split(path, ".")
 
 	t := modelValue
@@ -143,7 +199,7 @@ func getValueByPath(modelValue reflect.Value, path string) ([]reflect.Value, err
 			} else {
 				out := []reflect.Value{}
 				for idx := 0; idx < t.Len(); idx++ {
-					vals, err := getValueByPath(t.Index(idx), strings.Join(parts[1:], "."))
+					vals, err := getValueByPath(t.Index(idx), strings.Join(parts[i:], "."))
 					if err != nil {
 						return nil, err
 					}
@@ -153,6 +209,9 @@ func getValueByPath(modelValue reflect.Value, path string) ([]reflect.Value, err
 			}
 
 		case reflect.Pointer:
+			if t.IsNil() {
+				return []reflect.Value{}, nil
+			}
 			t = t.Elem()
 		default:
 			return nil, fmt.Errorf("invalid path part: %s", parts[i])
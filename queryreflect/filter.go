@@ -1,12 +1,20 @@
 package queryreflect
 
 import (
+	"fmt"
 	"reflect"
+	"regexp"
+	"time"
 
 	"github.com/royalcat/query"
 )
 
+// ApplyFilter returns values matching the filter, relative times are resolved with the current time.
 func ApplyFilter[D any](f query.Filter, in []D) ([]D, error) {
+	f, err := f.Resolve(time.Now())
+	if err != nil {
+		return nil, err
+	}
 	cond, err := generateReflectFilter[D](f)
 	if err != nil {
 		return nil, err
@@ -27,25 +35,186 @@ func ApplyFilter[D any](f query.Filter, in []D) ([]D, error) {
 type conditionErr[D any] func(v D) (bool, error)
 
 func generateReflectFilter[D any](f query.Filter) (conditionErr[D], error) {
-	conditions := []conditionErr[D]{}
+	conditions := make([]conditionErr[D], 0, len(f))
 
 	for _, filter := range f {
-		f := func(data D) (bool, error) {
-			vs1, err := getValueByPath(reflect.ValueOf(data), filter.Field)
+		c, err := generateReflectCondition[D](filter)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, c)
+	}
+
+	return allConditions(conditions), nil
+}
+
+func generateReflectCondition[D any](filter query.FieldFilter) (conditionErr[D], error) {
+	if group, ok := filter.Group(); ok {
+		conditions := make([]conditionErr[D], 0, len(group))
+		for _, g := range group {
+			c, err := generateReflectFilter[D](g)
 			if err != nil {
-				return false, err
+				return nil, err
+			}
+			conditions = append(conditions, c)
+		}
+
+		switch filter.Op {
+		case query.OperatorAnd:
+			return allConditions(conditions), nil
+		case query.OperatorOr:
+			return anyCondition(conditions), nil
+		case query.OperatorNot:
+			c := anyCondition(conditions)
+			return func(v D) (bool, error) {
+				res, err := c(v)
+				return !res, err
+			}, nil
+		default:
+			return nil, fmt.Errorf("unknown group operator: %s", filter.Op)
+		}
+	}
+
+	switch filter.Op {
+	case query.OperatorIsNull, query.OperatorExists:
+		return presenceCondition[D](filter), nil
+	}
+	if elem, ok := filter.Elem(); ok {
+		return elemCondition[D](filter.Field, elem)
+	}
+	if ref, ok := filter.Value.(query.FieldRef); ok {
+		return fieldRefCondition[D](filter, ref), nil
+	}
+
+	value := reflect.ValueOf(filter.Value)
+	if filter.Op == query.OperatorRegex {
+		pattern, _ := filter.Value.(string)
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex for %s: %w", filter.Field, err)
+		}
+		value = reflect.ValueOf(re)
+	}
+
+	compare := func(v1 reflect.Value) bool {
+		return reflectCompare(filter.Op, v1, value)
+	}
+	if _, ok := query.LookupOperator(filter.Op); ok {
+		predicate, ok := customOperator(filter.Op)
+		if !ok {
+			return nil, fmt.Errorf("operator %s is not registered in queryreflect", filter.Op)
+		}
+		compare = func(v1 reflect.Value) bool {
+			return predicate(v1, filter.Value)
+		}
+	}
+
+	var zero D
+	_, isMatcher := any(zero).(Matcher)
+
+	return func(data D) (bool, error) {
+		if isMatcher {
+			if res, ok := any(data).(Matcher).QueryMatch(filter); ok {
+				return res, nil
+			}
+		}
+
+		vs1, err := getValueByPath(reflect.ValueOf(data), filter.Field)
+		if err != nil {
+			return false, err
+		}
+		for _, v1 := range vs1 {
+			v1 = deref(v1)
+			if v1.IsValid() && compare(v1) {
+				return true, nil
 			}
-			for _, v1 := range vs1 {
-				if reflectCompare(filter.Op, v1, reflect.ValueOf(filter.Value)) {
+		}
+		return false, nil
+	}, nil
+}
+
+// elemCondition matches when a single element of a slice on the path matches elem.
+func elemCondition[D any](field string, elem query.Filter) (conditionErr[D], error) {
+	cond, err := generateReflectFilter[any](elem)
+	if err != nil {
+		return nil, err
+	}
+	return func(data D) (bool, error) {
+		vs, err := getValueByPath(reflect.ValueOf(data), field)
+		if err != nil {
+			return false, err
+		}
+		for _, v := range vs {
+			v = deref(v)
+			if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+				continue
+			}
+			for i := 0; i < v.Len(); i++ {
+				res, err := cond(v.Index(i).Interface())
+				if err != nil || res {
+					return res, err
+				}
+			}
+		}
+		return false, nil
+	}, nil
+}
+
+// fieldRefCondition compares the field with another field of the same value,
+// it matches when any pair of values on both paths does.
+func fieldRefCondition[D any](filter query.FieldFilter, ref query.FieldRef) conditionErr[D] {
+	return func(data D) (bool, error) {
+		v := reflect.ValueOf(data)
+		vs1, err := getValueByPath(v, filter.Field)
+		if err != nil {
+			return false, err
+		}
+		vs2, err := getValueByPath(v, string(ref))
+		if err != nil {
+			return false, err
+		}
+		for _, v1 := range vs1 {
+			v1 = deref(v1)
+			if !v1.IsValid() {
+				continue
+			}
+			for _, v2 := range vs2 {
+				v2 = deref(v2)
+				if v2.IsValid() && reflectCompare(filter.Op, v1, v2) {
 					return true, nil
 				}
 			}
-			return false, nil
 		}
+		return false, nil
+	}
+}
 
-		conditions = append(conditions, f)
+// presenceCondition matches OperatorIsNull and OperatorExists, a path through
+// a nil pointer doesn't exist and nil pointers, slices and maps are null.
+func presenceCondition[D any](filter query.FieldFilter) conditionErr[D] {
+	want, _ := filter.Value.(bool)
+	return func(data D) (bool, error) {
+		vs, err := getValueByPath(reflect.ValueOf(data), filter.Field)
+		if err != nil {
+			return false, err
+		}
+		if filter.Op == query.OperatorExists {
+			return (len(vs) > 0) == want, nil
+		}
+
+		null := true
+		for _, v := range vs {
+			v = deref(v)
+			if v.IsValid() && !((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.IsNil()) {
+				null = false
+				break
+			}
+		}
+		return null == want, nil
 	}
+}
 
+func allConditions[D any](conditions []conditionErr[D]) conditionErr[D] {
 	return func(v D) (bool, error) {
 		for _, c := range conditions {
 			res, err := c(v)
@@ -57,5 +226,20 @@ func generateReflectFilter[D any](f query.Filter) (conditionErr[D], error) {
 			}
 		}
 		return true, nil
-	}, nil
+	}
+}
+
+func anyCondition[D any](conditions []conditionErr[D]) conditionErr[D] {
+	return func(v D) (bool, error) {
+		for _, c := range conditions {
+			res, err := c(v)
+			if err != nil {
+				return false, err
+			}
+			if res {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
 }
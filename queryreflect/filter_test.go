@@ -2,6 +2,7 @@ package queryreflect_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/royalcat/query"
 	"github.com/royalcat/query/queryreflect"
@@ -90,3 +91,297 @@ func BenchmarkApplyFilter(b *testing.B) {
 	}
 
 }
+
+func TestApplyFilterGroups(t *testing.T) {
+	t.Parallel()
+
+	type testStruct struct {
+		ID       int    `json:"id"`
+		Status   string `json:"status"`
+		Assignee string `json:"assignee"`
+		Archived bool   `json:"archived"`
+	}
+
+	require := require.New(t)
+	data := []testStruct{
+		{ID: 1, Status: "open", Assignee: "bob"},
+		{ID: 2, Status: "closed", Assignee: "me"},
+		{ID: 3, Status: "closed", Assignee: "bob"},
+		{ID: 4, Status: "open", Assignee: "me", Archived: true},
+	}
+	f := query.Filter{
+		query.Or(
+			query.Filter{{Field: "status", Op: query.OperatorEqual, Value: "open"}},
+			query.Filter{{Field: "assignee", Op: query.OperatorEqual, Value: "me"}},
+		),
+		query.Not(
+			query.Filter{{Field: "archived", Op: query.OperatorEqual, Value: true}},
+		),
+	}
+
+	out, err := queryreflect.ApplyFilter(f, data)
+	require.NoError(err)
+	require.Equal([]testStruct{
+		{ID: 1, Status: "open", Assignee: "bob"},
+		{ID: 2, Status: "closed", Assignee: "me"},
+	}, out)
+
+	out, err = queryreflect.ApplyFilter(query.Filter{query.Or()}, data)
+	require.NoError(err)
+	require.Empty(out)
+
+	out, err = queryreflect.ApplyFilter(query.Filter{query.And()}, data)
+	require.NoError(err)
+	require.Equal(data, out)
+}
+
+func TestApplyFilterRange(t *testing.T) {
+	t.Parallel()
+
+	type testStruct struct {
+		Age int `json:"age"`
+	}
+
+	require := require.New(t)
+	data := []testStruct{{Age: 10}, {Age: 18}, {Age: 40}, {Age: 65}, {Age: 70}}
+	f := query.Filter{
+		{Field: "age", Op: query.OperatorGreaterOrEqual, Value: 18},
+		{Field: "age", Op: query.OperatorLessOrEqual, Value: 65},
+	}
+
+	out, err := queryreflect.ApplyFilter(f, data)
+	require.NoError(err)
+	require.Equal([]testStruct{{Age: 18}, {Age: 40}, {Age: 65}}, out)
+}
+
+func TestApplyFilterExtendedOperators(t *testing.T) {
+	t.Parallel()
+
+	type testStruct struct {
+		Name   string   `json:"name"`
+		Age    uint     `json:"age"`
+		Tags   []string `json:"tags"`
+		Parent *int     `json:"parent"`
+	}
+
+	require := require.New(t)
+	parent := 1
+	data := []testStruct{
+		{Name: "John", Age: 20, Tags: []string{"a"}, Parent: &parent},
+		{Name: "Joan", Age: 30, Tags: nil, Parent: nil},
+		{Name: "Bob", Age: 40, Tags: []string{"a", "b"}, Parent: nil},
+	}
+
+	names := func(f query.Filter) []string {
+		out, err := queryreflect.ApplyFilter(f, data)
+		require.NoError(err)
+		names := []string{}
+		for _, v := range out {
+			names = append(names, v.Name)
+		}
+		return names
+	}
+
+	require.Equal([]string{"John", "Bob"}, names(query.Filter{{Field: "age", Op: query.OperatorNotIn, Value: []uint{30}}}))
+	require.Equal([]string{"Joan"}, names(query.Filter{{Field: "tags", Op: query.OperatorNotIn, Value: []string{"a"}}}))
+	require.Equal([]string{"John", "Joan"}, names(query.Filter{{Field: "age", Op: query.OperatorBetween, Value: []uint{20, 30}}}))
+	require.Equal([]string{"John", "Joan"}, names(query.Filter{{Field: "name", Op: query.OperatorPrefix, Value: "Jo"}}))
+	require.Equal([]string{"John"}, names(query.Filter{{Field: "name", Op: query.OperatorSuffix, Value: "hn"}}))
+	require.Equal([]string{"Joan", "Bob"}, names(query.Filter{{Field: "name", Op: query.OperatorRegex, Value: "^(J|B)o[ab]"}}))
+	require.Equal([]string{"Joan", "Bob"}, names(query.Filter{{Field: "parent", Op: query.OperatorIsNull, Value: true}}))
+	require.Equal([]string{"John", "Bob"}, names(query.Filter{{Field: "tags", Op: query.OperatorIsNull, Value: false}}))
+	require.Equal([]string{"John", "Joan", "Bob"}, names(query.Filter{{Field: "parent", Op: query.OperatorExists, Value: true}}))
+
+	_, err := queryreflect.ApplyFilter(query.Filter{{Field: "name", Op: query.OperatorRegex, Value: "("}}, data)
+	require.Error(err)
+}
+
+func TestApplyFilterArrayOperators(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Price int `json:"price"`
+		Qty   int `json:"qty"`
+	}
+	type order struct {
+		ID     int      `json:"id"`
+		Items  []item   `json:"items"`
+		Tags   []string `json:"tags"`
+		Scores []int    `json:"scores"`
+	}
+
+	require := require.New(t)
+	data := []order{
+		{ID: 1, Items: []item{{Price: 20, Qty: 10}, {Price: 5, Qty: 1}}, Tags: []string{"a", "b"}, Scores: []int{70, 90}},
+		{ID: 2, Items: []item{{Price: 20, Qty: 1}}, Tags: []string{"a"}, Scores: []int{82}},
+		{ID: 3, Items: nil, Tags: nil, Scores: nil},
+	}
+
+	ids := func(f query.Filter) []int {
+		out, err := queryreflect.ApplyFilter(f, data)
+		require.NoError(err)
+		ids := []int{}
+		for _, v := range out {
+			ids = append(ids, v.ID)
+		}
+		return ids
+	}
+
+	// conditions on different elements match without elemMatch
+	require.Equal([]int{1, 2}, ids(query.Filter{
+		{Field: "items.price", Op: query.OperatorGreaterOrEqual, Value: 10},
+		{Field: "items.qty", Op: query.OperatorLess, Value: 5},
+	}))
+	require.Equal([]int{2}, ids(query.Filter{query.ElemMatch("items", query.Filter{
+		{Field: "price", Op: query.OperatorGreaterOrEqual, Value: 10},
+		{Field: "qty", Op: query.OperatorLess, Value: 5},
+	})}))
+	require.Equal([]int{2}, ids(query.Filter{query.ElemMatch("scores", query.Filter{
+		{Field: "", Op: query.OperatorGreaterOrEqual, Value: 80},
+		{Field: "", Op: query.OperatorLess, Value: 85},
+	})}))
+	require.Equal([]int{1}, ids(query.Filter{{Field: "tags", Op: query.OperatorAll, Value: []string{"b", "a"}}}))
+	require.Equal([]int{2}, ids(query.Filter{{Field: "tags", Op: query.OperatorSize, Value: 1}}))
+	require.Equal([]int{3}, ids(query.Filter{{Field: "items", Op: query.OperatorSize, Value: 0}}))
+}
+
+func TestApplyFilterFieldRef(t *testing.T) {
+	t.Parallel()
+
+	type product struct {
+		Name         string `json:"name"`
+		Stock        int    `json:"stock"`
+		ReorderLevel *int   `json:"reorder_level"`
+	}
+
+	require := require.New(t)
+	five, ten := 5, 10
+	data := []product{
+		{Name: "a", Stock: 3, ReorderLevel: &five},
+		{Name: "b", Stock: 10, ReorderLevel: &ten},
+		{Name: "c", Stock: 1, ReorderLevel: nil},
+	}
+
+	out, err := queryreflect.ApplyFilter(query.Filter{
+		{Field: "stock", Op: query.OperatorLess, Value: query.FieldRef("reorder_level")},
+	}, data)
+	require.NoError(err)
+	require.Equal([]product{data[0]}, out)
+
+	out, err = queryreflect.ApplyFilter(query.Filter{
+		{Field: "reorder_level", Op: query.OperatorEqual, Value: query.FieldRef("stock")},
+	}, data)
+	require.NoError(err)
+	require.Equal([]product{data[1]}, out)
+	type auction struct {
+		Reserve int   `json:"reserve"`
+		Bids    []int `json:"bids"`
+	}
+	auctions := []auction{
+		{Reserve: 10, Bids: []int{5, 12}},
+		{Reserve: 10, Bids: []int{5, 8}},
+		{Reserve: 10, Bids: nil},
+	}
+	outAuctions, err := queryreflect.ApplyFilter(query.Filter{
+		{Field: "bids", Op: query.OperatorGreaterOrEqual, Value: query.FieldRef("reserve")},
+	}, auctions)
+	require.NoError(err)
+	require.Equal([]auction{auctions[0]}, outAuctions)
+}
+
+func TestApplyFilterRelativeTime(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		Name string    `json:"name"`
+		At   time.Time `json:"at"`
+	}
+
+	require := require.New(t)
+	now := time.Now()
+	data := []event{
+		{Name: "old", At: now.AddDate(0, 0, -10)},
+		{Name: "recent", At: now.AddDate(0, 0, -2)},
+	}
+
+	out, err := queryreflect.ApplyFilter(query.Filter{
+		{Field: "at", Op: query.OperatorGreaterOrEqual, Value: query.RelativeTime{Expr: "now-7d", Location: time.UTC}},
+	}, data)
+	require.NoError(err)
+	require.Equal([]event{data[1]}, out)
+
+	_, err = queryreflect.ApplyFilter(query.Filter{
+		{Field: "at", Op: query.OperatorGreaterOrEqual, Value: query.RelativeTime{Expr: "now-7x", Location: time.UTC}},
+	}, data)
+	require.ErrorIs(err, query.ErrInvalidValue)
+}
+
+func TestApplyFilterTimeRange(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		Name string    `json:"name"`
+		At   time.Time `json:"at"`
+	}
+
+	require := require.New(t)
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, loc)
+	data := []event{
+		{Name: "before", At: start.Add(-time.Nanosecond)},
+		{Name: "start", At: start},
+		{Name: "end", At: start.AddDate(0, 0, 1).Add(-time.Nanosecond)},
+		{Name: "after", At: start.AddDate(0, 0, 1)},
+	}
+	day := query.TimeRange{Start: start, End: start.AddDate(0, 0, 1)}
+
+	out, err := queryreflect.ApplyFilter(query.Filter{
+		{Field: "at", Op: query.OperatorEqual, Value: day},
+	}, data)
+	require.NoError(err)
+	require.Equal(data[1:3], out)
+
+	out, err = queryreflect.ApplyFilter(query.Filter{
+		{Field: "at", Op: query.OperatorNotEqual, Value: day},
+	}, data)
+	require.NoError(err)
+	require.Equal([]event{data[0], data[3]}, out)
+
+	out, err = queryreflect.ApplyFilter(query.Filter{
+		{Field: "at", Op: query.OperatorLessOrEqual, Value: day},
+	}, data)
+	require.NoError(err)
+	require.Equal(data[:3], out)
+}
+
+func TestApplyFilterTimePrecision(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		Name string    `json:"name"`
+		At   time.Time `json:"at"`
+	}
+
+	require := require.New(t)
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	data := []event{
+		{Name: "at", At: at},
+		{Name: "later", At: at.Add(time.Nanosecond)},
+	}
+
+	out, err := queryreflect.ApplyFilter(query.Filter{
+		{Field: "at", Op: query.OperatorGreater, Value: at},
+	}, data)
+	require.NoError(err)
+	require.Equal([]event{data[1]}, out)
+
+	out, err = queryreflect.ApplyFilter(query.Filter{
+		{Field: "at", Op: query.OperatorEqual, Value: at},
+	}, data)
+	require.NoError(err)
+	require.Equal([]event{data[0]}, out)
+
+	matched, ok := queryreflect.MatchTime(query.OperatorGreater, data[1].At, at)
+	require.True(ok)
+	require.True(matched)
+}
@@ -0,0 +1,160 @@
+package queryreflect_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/royalcat/query"
+	"github.com/royalcat/query/queryreflect"
+	"github.com/stretchr/testify/require"
+)
+
+//go:generate go run ../cmd/querygen -type genModel
+
+type genLevel int
+
+type genPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type genModel struct {
+	ID      string    `json:"id"`
+	Age     int       `json:"age"`
+	Small   uint8     `json:"small"`
+	Score   float64   `json:"score"`
+	Active  bool      `json:"active"`
+	Level   genLevel  `json:"level"`
+	Tags    []string  `json:"tags"`
+	Created time.Time `json:"created_at"`
+	Point   genPoint  `json:"point"`
+	Nested  *struct {
+		Count *int `json:"count"`
+	} `json:"nested"`
+	Any any `json:"any"`
+}
+
+// genModelReflect has no generated methods and is matched with reflection only.
+type genModelReflect genModel
+
+func genModels() []genModel {
+	one, two := 1, 2
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []genModel{
+		{ID: "a1", Age: 30, Small: 1, Score: 1.5, Active: true, Level: 2, Tags: []string{"x", "y"}, Created: base, Point: genPoint{X: 1, Y: 2}, Nested: &struct {
+			Count *int `json:"count"`
+		}{Count: &two}, Any: nil},
+		{ID: "b2", Age: 20, Small: 3, Score: 2.5, Active: false, Level: 1, Tags: []string{"y"}, Created: base.Add(time.Hour), Point: genPoint{X: 3, Y: 0}, Nested: nil, Any: nil},
+		{ID: "c3", Age: 30, Small: 2, Score: 0.5, Active: true, Level: 3, Tags: nil, Created: base.Add(-time.Hour), Point: genPoint{X: 2, Y: 1}, Nested: &struct {
+			Count *int `json:"count"`
+		}{Count: &one}, Any: nil},
+	}
+}
+
+func TestGeneratedParseFilter(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	values := map[string][]string{
+		"id{substr}":       {"2"},
+		"age{gte}":         {"25"},
+		"small{in}":        {"1,3"},
+		"score{lt}":        {"3"},
+		"active":           {"true"},
+		"level{ne}":        {"1"},
+		"tags":             {"y"},
+		"created_at{gt}":   {"2023-12-31T00:00:00Z"},
+		"point.x{lte}":     {"3"},
+		"nested.count{eq}": {"2"},
+	}
+
+	expected, err := query.ParseFilter[genModel](values)
+	require.NoError(err)
+	f, err := parseGenModelFilter(values)
+	require.NoError(err)
+	require.Equal(expected, f)
+
+	_, err = parseGenModelFilter(map[string][]string{"age": {"old"}})
+	require.ErrorIs(err, query.ErrInvalidValue)
+	_, err = parseGenModelFilter(map[string][]string{"active{gt}": {"true"}})
+	require.ErrorIs(err, query.ErrOperatorNotAllowed)
+	_, err = parseGenModelFilter(map[string][]string{"unknown": {"1"}})
+	require.ErrorIs(err, query.ErrUnknownField)
+	_, err = parseGenModelFilter(map[string][]string{"age{lt}": {"$score"}})
+	require.ErrorIs(err, query.ErrInvalidValue)
+}
+
+func TestGeneratedMatchParity(t *testing.T) {
+	t.Parallel()
+
+	filters := []map[string][]string{
+		{"id{substr}": {"2"}},
+		{"age": {"30"}, "active": {"true"}},
+		{"small{in}": {"1,3"}},
+		{"score{gt}": {"1"}},
+		{"level{lte}": {"2"}},
+		{"tags": {"y"}},
+		{"tags{ne}": {"y"}},
+		{"tags{in}": {"x,z"}},
+		{"created_at{lt}": {"2024-01-01T00:30:00Z"}},
+		{"point.y{gte}": {"1"}},
+		{"nested.count{gt}": {"1"}},
+		{"age{substr}": {"3"}},
+		{"age{nin}": {"20,40"}},
+		{"tags{nin}": {"x"}},
+		{"score{between}": {"1,2.5"}},
+		{"id{prefix}": {"b"}},
+		{"id{suffix}": {"3"}},
+		{"id{regex}": {"^[ab]"}},
+		{"nested.count{isnull}": {"true"}},
+		{"nested{exists}": {"true"}},
+		{"tags{all}": {"x,y"}},
+		{"tags{size}": {"0"}},
+		{"age{gt}": {"$nested.count"}},
+		{"point.x{gte}": {"$point.y"}},
+	}
+
+	for _, values := range filters {
+		f, err := parseGenModelFilter(values)
+		require.NoError(t, err)
+
+		generated, err := queryreflect.ApplyFilter(f, genModels())
+		require.NoError(t, err)
+
+		models := []genModelReflect{}
+		for _, m := range genModels() {
+			models = append(models, genModelReflect(m))
+		}
+		reflected, err := queryreflect.ApplyFilter(f, models)
+		require.NoError(t, err)
+
+		require.Len(t, generated, len(reflected), values)
+		for i := range generated {
+			require.Equal(t, reflected[i].ID, generated[i].ID, values)
+		}
+	}
+}
+
+func TestGeneratedSortParity(t *testing.T) {
+	t.Parallel()
+
+	sorts := []string{"age,-id", "-created_at", "point.x", "nested.count,id", "-small,score"}
+	for _, v := range sorts {
+		s, err := query.ParseSort[genModel](v)
+		require.NoError(t, err)
+
+		generated, err := queryreflect.ApplySort(s, genModels())
+		require.NoError(t, err)
+
+		models := []genModelReflect{}
+		for _, m := range genModels() {
+			models = append(models, genModelReflect(m))
+		}
+		reflected, err := queryreflect.ApplySort(s, models)
+		require.NoError(t, err)
+
+		for i := range generated {
+			require.Equal(t, reflected[i].ID, generated[i].ID, v)
+		}
+	}
+}
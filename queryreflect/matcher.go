@@ -0,0 +1,136 @@
+package queryreflect
+
+import (
+	"cmp"
+	"strings"
+	"time"
+
+	"github.com/royalcat/query"
+)
+
+// Matcher is implemented by models that match filters without reflection,
+// usually by code generated with querygen. ok is false when the filter must
+// be matched with reflection.
+type Matcher interface {
+	QueryMatch(f query.FieldFilter) (matched, ok bool)
+}
+
+// Comparer is implemented by models that order by a sort key without reflection.
+// ok is false when the key must be compared with reflection.
+type Comparer[D any] interface {
+	QueryCompare(other D, key string) (c int, ok bool)
+}
+
+// MatchOrdered matches a value of an ordered type against a filter value.
+func MatchOrdered[T cmp.Ordered](op query.Operator, v T, value any) (matched, ok bool) {
+	return matchValue(op, v, value, cmp.Compare[T])
+}
+
+// MatchString is MatchOrdered with support for OperatorSubString,
+// OperatorPrefix and OperatorSuffix. OperatorRegex is matched with reflection.
+func MatchString[T ~string](op query.Operator, v T, value any) (matched, ok bool) {
+	var match func(s, substr string) bool
+	switch op {
+	case query.OperatorSubString:
+		match = strings.Contains
+	case query.OperatorPrefix:
+		match = strings.HasPrefix
+	case query.OperatorSuffix:
+		match = strings.HasSuffix
+	default:
+		return MatchOrdered(op, v, value)
+	}
+
+	s, ok := value.(T)
+	if !ok {
+		return false, false
+	}
+	return match(string(v), string(s)), true
+}
+
+// MatchBool matches a bool value, only equality operators are supported.
+func MatchBool[T ~bool](op query.Operator, v T, value any) (matched, ok bool) {
+	switch op {
+	case query.OperatorDefault, query.OperatorEqual, query.OperatorNotEqual, query.OperatorIn, query.OperatorNotIn:
+		return matchValue(op, v, value, func(a, b T) int {
+			if a == b {
+				return 0
+			}
+			return 1
+		})
+	}
+	return false, false
+}
+
+// MatchTime matches a time value.
+func MatchTime(op query.Operator, v time.Time, value any) (matched, ok bool) {
+	return matchValue(op, v, value, time.Time.Compare)
+}
+
+// MatchSlice matches when any element of vs matches,
+// OperatorNotIn matches when no element is in the list,
+// OperatorAll and OperatorSize apply to the whole slice.
+func MatchSlice[T any](op query.Operator, vs []T, value any, match func(query.Operator, T, any) (bool, bool)) (matched, ok bool) {
+	switch op {
+	case query.OperatorNotIn:
+		matched, ok := MatchSlice(query.OperatorIn, vs, value, match)
+		return !matched, ok
+	case query.OperatorSize:
+		n, ok := value.(int)
+		return len(vs) == n, ok
+	case query.OperatorAll:
+		vals, ok := value.([]T)
+		if !ok {
+			return false, false
+		}
+		for _, val := range vals {
+			matched, ok := MatchSlice(query.OperatorEqual, vs, val, match)
+			if !ok || !matched {
+				return false, ok
+			}
+		}
+		return true, true
+	case query.OperatorElemMatch:
+		return false, false
+	}
+	if _, ok := query.LookupOperator(op); ok {
+		return false, false
+	}
+	for _, v := range vs {
+		matched, ok := match(op, v, value)
+		if !ok || matched {
+			return matched, ok
+		}
+	}
+	return false, true
+}
+
+func matchValue[T any](op query.Operator, v T, value any, compare func(T, T) int) (matched, ok bool) {
+	switch op {
+	case query.OperatorIn, query.OperatorNotIn:
+		vals, ok := value.([]T)
+		if !ok {
+			return false, false
+		}
+		found := false
+		for _, val := range vals {
+			if compare(v, val) == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (op == query.OperatorIn), true
+	case query.OperatorBetween:
+		vals, ok := value.([]T)
+		if !ok || len(vals) != 2 {
+			return false, false
+		}
+		return compare(v, vals[0]) >= 0 && compare(v, vals[1]) <= 0, true
+	}
+
+	val, ok := value.(T)
+	if !ok {
+		return false, false
+	}
+	return compareResult(op, compare(v, val))
+}
@@ -0,0 +1,35 @@
+package queryreflect
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/royalcat/query"
+)
+
+// Predicate matches a field value against a filter value of a custom operator.
+// v is the field value with pointers dereferenced, a whole slice for slice fields.
+type Predicate func(v reflect.Value, value any) bool
+
+var operators sync.Map // query.Operator -> Predicate
+
+// RegisterOperator sets the predicate of a custom operator,
+// the operator must be registered with query.RegisterOperator first.
+func RegisterOperator(op query.Operator, fn Predicate) error {
+	if _, ok := query.LookupOperator(op); !ok {
+		return fmt.Errorf("operator %q is not registered in query", op)
+	}
+	if _, loaded := operators.LoadOrStore(op, fn); loaded {
+		return fmt.Errorf("operator %q is already registered", op)
+	}
+	return nil
+}
+
+func customOperator(op query.Operator) (Predicate, bool) {
+	fn, ok := operators.Load(op)
+	if !ok {
+		return nil, false
+	}
+	return fn.(Predicate), true
+}
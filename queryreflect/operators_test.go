@@ -0,0 +1,40 @@
+package queryreflect_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/royalcat/query/queryreflect"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterOperator(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	const startsWith query.Operator = "reflect_startswith"
+	require.Error(queryreflect.RegisterOperator(startsWith, nil))
+	require.NoError(query.RegisterOperator(startsWith, query.OperatorDef{Parse: nil, List: false, Allowed: nil}))
+	require.NoError(queryreflect.RegisterOperator(startsWith, func(v reflect.Value, value any) bool {
+		s, ok := value.(string)
+		return ok && v.Kind() == reflect.String && strings.HasPrefix(v.String(), s)
+	}))
+
+	type testStruct struct {
+		Name string `json:"name"`
+	}
+	data := []testStruct{{Name: "alpha"}, {Name: "beta"}, {Name: "alps"}}
+
+	f, err := query.ParseFilter[testStruct](map[string][]string{"name{reflect_startswith}": {"al"}})
+	require.NoError(err)
+	out, err := queryreflect.ApplyFilter(f, data)
+	require.NoError(err)
+	require.Equal([]testStruct{{Name: "alpha"}, {Name: "alps"}}, out)
+
+	const unknown query.Operator = "reflect_unknown"
+	require.NoError(query.RegisterOperator(unknown, query.OperatorDef{Parse: nil, List: false, Allowed: nil}))
+	_, err = queryreflect.ApplyFilter(query.Filter{{Field: "name", Op: unknown, Value: "x"}}, data)
+	require.Error(err)
+}
@@ -1,29 +1,66 @@
 package queryreflect
 
-import "github.com/royalcat/query"
+import (
+	"fmt"
+	"slices"
+
+	"github.com/royalcat/query"
+)
 
 func ApplyQuery[D any](q query.Query, in []D) ([]D, error) {
-	var err error
+	out, _, _, err := applyQuery(q, in)
+	return out, err
+}
+
+// ApplyQueryPage is ApplyQuery that also counts matching items and checks for a next page.
+func ApplyQueryPage[D any](q query.Query, in []D) (query.Page[D], error) {
+	out, total, hasNext, err := applyQuery(q, in)
+	if err != nil {
+		return query.Page[D]{}, err
+	}
+	return query.NewPage(q, out, uint64(total), hasNext)
+}
+
+// applyQuery returns the page of q, total is the number of items matching filter and search.
+func applyQuery[D any](q query.Query, in []D) (out []D, total int, hasNext bool, err error) {
+	if len(q.Pagination.After) > 0 && len(q.Pagination.After) != len(q.Sort) {
+		return nil, 0, false, fmt.Errorf("%w: cursor doesn't match sort", query.ErrInvalidCursor)
+	}
 	if len(q.Filter) > 0 {
 		in, err = ApplyFilter(q.Filter, in)
 		if err != nil {
-			return nil, err
+			return nil, 0, false, err
+		}
+	}
+	if q.Search != "" {
+		in, err = ApplySearch(q.Search, in)
+		if err != nil {
+			return nil, 0, false, err
 		}
 	}
+	total = len(in)
+	if len(q.Pagination.After) > 0 {
+		after := afterCursor[D](q.Sort, q.Pagination.After)
+		in = slices.DeleteFunc(slices.Clone(in), func(v D) bool { return !after(v) })
+	}
 	if len(q.Sort) > 0 {
 		in, err = ApplySort(q.Sort, in)
 		if err != nil {
-			return nil, err
+			return nil, 0, false, err
 		}
 	}
 
+	hasNext = q.Pagination.Limit != 0 && uint64(len(in)) > q.Pagination.Offset+q.Pagination.Limit
 	if len(in) <= int(q.Pagination.Offset) {
-		return []D{}, nil
+		in = []D{}
 	} else if len(in) < int(q.Pagination.Offset+q.Pagination.Limit) || q.Pagination.Limit == 0 {
-		return in[q.Pagination.Offset:], nil
+		in = in[q.Pagination.Offset:]
 	} else {
-		return in[q.Pagination.Offset : q.Pagination.Offset+q.Pagination.Limit], nil
+		in = in[q.Pagination.Offset : q.Pagination.Offset+q.Pagination.Limit]
 	}
+
+	out, err = ApplySelect(q.SelectFields(), in)
+	return out, total, hasNext, err
 }
 
 type PageGetter[D any] func(q query.Query) ([]D, error)
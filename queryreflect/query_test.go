@@ -1,6 +1,7 @@
 package queryreflect_test
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/royalcat/query"
@@ -77,3 +78,193 @@ func TestApplyQueryArrayIndex(t *testing.T) {
 		{Names: []int{4}},
 	}, out)
 }
+
+func TestApplyQueryCursor(t *testing.T) {
+	t.Parallel()
+
+	type testStruct struct {
+		ID    int    `json:"id"`
+		Group string `json:"group"`
+	}
+
+	require := require.New(t)
+	data := []testStruct{
+		{ID: 1, Group: "a"},
+		{ID: 2, Group: "b"},
+		{ID: 3, Group: "a"},
+		{ID: 4, Group: "b"},
+		{ID: 5, Group: "a"},
+	}
+	q := query.Query{
+		Sort: query.Sort{
+			{Key: "group", Order: query.DESC},
+			{Key: "id", Order: query.ASC},
+		},
+		Pagination: query.Pagination{Limit: 2},
+	}
+
+	pages := [][]testStruct{}
+	for {
+		page, err := queryreflect.ApplyQuery(q, data)
+		require.NoError(err)
+		pages = append(pages, page)
+
+		next, err := query.NextCursor(q, page)
+		require.NoError(err)
+		if next == nil {
+			break
+		}
+		q.Pagination.After = next
+	}
+
+	require.Equal([][]testStruct{
+		{{ID: 2, Group: "b"}, {ID: 4, Group: "b"}},
+		{{ID: 1, Group: "a"}, {ID: 3, Group: "a"}},
+		{{ID: 5, Group: "a"}},
+	}, pages)
+}
+
+func TestApplyQueryScopeCursor(t *testing.T) {
+	t.Parallel()
+
+	type testStruct struct {
+		ID    int    `json:"id"`
+		Group string `json:"group"`
+	}
+
+	require := require.New(t)
+	data := []testStruct{
+		{ID: 1, Group: "a"},
+		{ID: 2, Group: "b"},
+		{ID: 3, Group: "a"},
+	}
+	user := query.Query{
+		Sort:       query.Sort{{Key: "id", Order: query.ASC}},
+		Pagination: query.Pagination{Limit: 2, After: query.Cursor{1}},
+	}
+	scope := query.Query{
+		Sort: query.Sort{{Key: "group", Order: query.ASC}},
+	}
+
+	q, _ := user.Scope(scope)
+	require.Equal(user.Sort, q.Sort)
+	out, err := queryreflect.ApplyQuery(q, data)
+	require.NoError(err)
+	require.Equal([]testStruct{data[1], data[2]}, out)
+
+	user.Pagination.After = nil
+	q, _ = user.Scope(scope)
+	require.Len(q.Sort, 2)
+}
+
+func TestApplyQuerySelect(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Price int    `json:"price"`
+		Name  string `json:"name"`
+	}
+	type testStruct struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Items []item `json:"items"`
+		Owner *item  `json:"owner"`
+	}
+
+	require := require.New(t)
+	owner := &item{Price: 1, Name: "owner"}
+	data := []*testStruct{
+		{ID: 2, Name: "b", Items: []item{{Price: 3, Name: "x"}}, Owner: owner},
+		{ID: 1, Name: "a", Items: nil, Owner: nil},
+	}
+	q := query.Query{
+		Sort:   query.Sort{{Key: "id", Order: query.ASC}},
+		Select: query.Fields{"items.0.price", "owner.name"},
+	}
+
+	out, err := queryreflect.ApplyQuery(q, data)
+	require.NoError(err)
+	require.Equal([]*testStruct{
+		{ID: 1},
+		{ID: 2, Items: []item{{Price: 3}}, Owner: &item{Name: "owner"}},
+	}, out)
+	require.Equal(&item{Price: 1, Name: "owner"}, owner)
+}
+
+func TestApplyQueryCursorNulls(t *testing.T) {
+	t.Parallel()
+
+	type task struct {
+		ID  int  `json:"id"`
+		Due *int `json:"due"`
+	}
+
+	require := require.New(t)
+	one, two := 1, 2
+	data := []task{{ID: 1, Due: nil}, {ID: 2, Due: &two}, {ID: 3, Due: nil}, {ID: 4, Due: &one}}
+	q := query.Query{
+		Sort: query.Sort{
+			{Key: "due", Order: query.ASC, Nulls: query.NullsLast},
+			{Key: "id", Order: query.ASC},
+		},
+		Pagination: query.Pagination{Limit: 3},
+	}
+
+	page, err := queryreflect.ApplyQuery(q, slices.Clone(data))
+	require.NoError(err)
+	require.Equal([]task{data[3], data[1], data[0]}, page)
+
+	q.Pagination.After, err = query.NextCursor(q, page)
+	require.NoError(err)
+	page, err = queryreflect.ApplyQuery(q, slices.Clone(data))
+	require.NoError(err)
+	require.Equal([]task{data[2]}, page)
+}
+
+func TestApplyQueryPage(t *testing.T) {
+	t.Parallel()
+
+	type testStruct struct {
+		ID    int    `json:"id"`
+		Group string `json:"group"`
+	}
+
+	require := require.New(t)
+	data := []testStruct{
+		{ID: 1, Group: "a"},
+		{ID: 2, Group: "b"},
+		{ID: 3, Group: "a"},
+		{ID: 4, Group: "b"},
+		{ID: 5, Group: "a"},
+	}
+
+	for _, cursor := range []bool{false, true} {
+		q := query.Query{
+			Filter:     query.Filter{{Field: "group", Op: query.OperatorEqual, Value: "a"}},
+			Sort:       query.Sort{{Key: "id", Order: query.DESC}},
+			Pagination: query.Pagination{Limit: 2},
+		}
+		if cursor {
+			q.Pagination.After = query.Cursor{10}
+		}
+
+		page, err := queryreflect.ApplyQueryPage(q, data)
+		require.NoError(err)
+		require.Equal([]testStruct{data[4], data[2]}, page.Items)
+		require.Equal(uint64(3), page.Total)
+		require.True(page.HasNext)
+		require.Equal(query.Cursor{3}, page.Next)
+
+		q, ok := page.NextQuery(q)
+		require.True(ok)
+		page, err = queryreflect.ApplyQueryPage(q, data)
+		require.NoError(err)
+		require.Equal([]testStruct{data[0]}, page.Items)
+		require.Equal(uint64(3), page.Total)
+		require.False(page.HasNext)
+		require.Nil(page.Next)
+
+		_, ok = page.NextQuery(q)
+		require.False(ok)
+	}
+}
@@ -0,0 +1,74 @@
+package queryreflect
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/royalcat/query"
+)
+
+// ApplySearch keeps items where every search token is found, case-insensitive,
+// in at least one of the search fields of the model.
+func ApplySearch[D any](search string, in []D) ([]D, error) {
+	tokens := query.SearchTokens(search)
+	if len(tokens) == 0 {
+		return in, nil
+	}
+
+	fields, err := query.SearchFields[D]()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, query.ErrNoSearchFields
+	}
+
+	out := []D{}
+	for _, v := range in {
+		texts := []string{}
+		for _, f := range fields {
+			vs, err := getValueByPath(reflect.ValueOf(v), f.Path)
+			if err != nil {
+				return nil, err
+			}
+			for _, fv := range vs {
+				texts = appendStrings(texts, fv)
+			}
+		}
+
+		if matchTokens(tokens, texts) {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func appendStrings(out []string, v reflect.Value) []string {
+	v = deref(v)
+	switch {
+	case !v.IsValid():
+	case v.Kind() == reflect.String:
+		out = append(out, strings.ToLower(v.String()))
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			out = appendStrings(out, v.Index(i))
+		}
+	}
+	return out
+}
+
+func matchTokens(tokens, texts []string) bool {
+	for _, token := range tokens {
+		found := false
+		for _, text := range texts {
+			if strings.Contains(text, token) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package queryreflect_test
+
+import (
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/royalcat/query/queryreflect"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyQuerySearch(t *testing.T) {
+	t.Parallel()
+
+	type author struct {
+		Name string `json:"name" search:""`
+	}
+	type testStruct struct {
+		ID     int      `json:"id"`
+		Title  string   `json:"title" search:"10"`
+		Tags   []string `json:"tags" search:""`
+		Author *author  `json:"author"`
+	}
+
+	require := require.New(t)
+	data := []testStruct{
+		{ID: 1, Title: "Go generics", Tags: []string{"lang"}, Author: &author{Name: "Rob"}},
+		{ID: 2, Title: "Mongo queries", Tags: []string{"db", "GO"}},
+		{ID: 3, Title: "Rust", Tags: nil, Author: &author{Name: "Graydon"}},
+	}
+
+	out, err := queryreflect.ApplyQuery(query.Query{Search: "go"}, data)
+	require.NoError(err)
+	require.Equal([]testStruct{data[0], data[1]}, out)
+
+	out, err = queryreflect.ApplyQuery(query.Query{Search: "GO  rob"}, data)
+	require.NoError(err)
+	require.Equal([]testStruct{data[0]}, out)
+
+	fields, err := query.SearchFields[testStruct]()
+	require.NoError(err)
+	require.Equal([]query.SearchField{
+		{Path: "title", Weight: 10},
+		{Path: "tags", Weight: 1},
+		{Path: "author.name", Weight: 1},
+	}, fields)
+
+	type untagged struct {
+		Title string `json:"title"`
+	}
+	_, err = queryreflect.ApplyQuery(query.Query{Search: "go"}, []untagged{{Title: "Go"}})
+	require.ErrorIs(err, query.ErrNoSearchFields)
+}
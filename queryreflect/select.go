@@ -0,0 +1,91 @@
+package queryreflect
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/royalcat/query"
+)
+
+// ApplySelect returns copies of the items with all fields not listed in fields set to zero value.
+// Array indexes in paths are ignored, "items.0.price" selects price of every item.
+func ApplySelect[D any](fields query.Fields, in []D) ([]D, error) {
+	if len(fields) == 0 {
+		return in, nil
+	}
+
+	tree := selectTree{}
+	for _, f := range fields {
+		tree.add(f)
+	}
+
+	out := make([]D, 0, len(in))
+	for _, v := range in {
+		p := tree.project(reflect.ValueOf(&v).Elem())
+		out = append(out, p.Interface().(D))
+	}
+	return out, nil
+}
+
+// selectTree holds selected paths by json names, empty node selects the whole value.
+type selectTree map[string]selectTree
+
+func (t selectTree) add(path string) {
+	node := t
+	for _, part := range strings.Split(path, ".") {
+		if _, err := strconv.Atoi(part); err == nil {
+			continue
+		}
+		child, ok := node[part]
+		if ok && len(child) == 0 {
+			return // parent already selected as a whole
+		}
+		if !ok {
+			child = selectTree{}
+			node[part] = child
+		}
+		node = child
+	}
+	for k := range node {
+		delete(node, k)
+	}
+}
+
+func (t selectTree) project(v reflect.Value) reflect.Value {
+	if len(t) == 0 {
+		return v
+	}
+
+	out := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Struct:
+		typ := v.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+			if sub, ok := t[name]; ok && typ.Field(i).IsExported() {
+				out.Field(i).Set(sub.project(v.Field(i)))
+			}
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			elem := reflect.New(v.Type().Elem())
+			elem.Elem().Set(t.project(v.Elem()))
+			out.Set(elem)
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			out.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				out.Index(i).Set(t.project(v.Index(i)))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(t.project(v.Index(i)))
+		}
+	default:
+		return v
+	}
+	return out
+}
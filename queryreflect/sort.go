@@ -8,47 +8,79 @@ import (
 )
 
 func ApplySort[D any](s query.Sort, in []D) ([]D, error) {
-	cmps := generateReflectSort[D](s)
-
-	for _, cmp := range cmps {
-		slices.SortFunc(in, cmp)
-	}
-
+	slices.SortStableFunc(in, generateReflectSort[D](s))
 	return in, nil
 }
 
 type compare[D any] func(v1, v2 D) int
 
-func generateReflectSort[D any](s query.Sort) []compare[D] {
-	out := make([]compare[D], 0, len(s))
-	for _, f := range s {
-
-		if f.Order == query.ASC {
-			out = append(out, func(v1, v2 D) int {
-				vs1, _ := getValueByPath(reflect.ValueOf(v1), f.Key)
-				vs2, _ := getValueByPath(reflect.ValueOf(v2), f.Key)
-				if reflectCompare(query.OperatorGreater, vs1[0], vs2[0]) {
-					return 1
-				} else if reflectCompare(query.OperatorLess, vs1[0], vs2[0]) {
-					return -1
-				} else {
-					return 0
-				}
-			})
-		} else {
-			out = append(out, func(v1, v2 D) int {
-				vs1, _ := getValueByPath(reflect.ValueOf(v1), f.Key)
-				vs2, _ := getValueByPath(reflect.ValueOf(v2), f.Key)
-				if reflectCompare(query.OperatorGreater, vs1[0], vs2[0]) {
-					return -1
-				} else if reflectCompare(query.OperatorLess, vs1[0], vs2[0]) {
-					return 1
-				} else {
-					return 0
+// generateReflectSort compares by every sort key in turn, missing and nil
+// values are placed by the null order of the key, see query.NullOrder.
+func generateReflectSort[D any](s query.Sort) compare[D] {
+	var zero D
+	_, isComparer := any(zero).(Comparer[D])
+
+	return func(v1, v2 D) int {
+		for _, f := range s {
+			c, ok := 0, false
+			if isComparer && f.Nulls == query.NullsDefault {
+				c, ok = any(v1).(Comparer[D]).QueryCompare(v2, f.Key)
+				if f.Order == query.DESC {
+					c = -c
 				}
-			})
+			}
+			if !ok {
+				c = compareSortField(f,
+					sortValue(reflect.ValueOf(v1), f.Key),
+					sortValue(reflect.ValueOf(v2), f.Key),
+				)
+			}
+			if c != 0 {
+				return c
+			}
 		}
+		return 0
 	}
+}
 
-	return out
+// afterCursor reports whether v is sorted strictly after the cursor.
+func afterCursor[D any](s query.Sort, c query.Cursor) func(v D) bool {
+	return func(v D) bool {
+		for i, f := range s {
+			cmp := compareSortField(f,
+				sortValue(reflect.ValueOf(v), f.Key),
+				deref(reflect.ValueOf(c[i])),
+			)
+			if cmp != 0 {
+				return cmp > 0
+			}
+		}
+		return false
+	}
+}
+
+func sortValue(v reflect.Value, key string) reflect.Value {
+	vs, _ := getValueByPath(v, key)
+	if len(vs) == 0 {
+		return reflect.Value{}
+	}
+	return deref(vs[0])
+}
+
+// compareSortField orders two values of a sort key in its order,
+// invalid values are nulls placed by the null order of the key.
+func compareSortField(f query.SortField, v1, v2 reflect.Value) int {
+	switch {
+	case !v1.IsValid() && !v2.IsValid():
+		return 0
+	case !v1.IsValid() && f.NullsAfter(), !v2.IsValid() && !f.NullsAfter():
+		return 1
+	case !v1.IsValid(), !v2.IsValid():
+		return -1
+	}
+	c, _ := compareValues(v1, v2)
+	if f.Order == query.DESC {
+		c = -c
+	}
+	return c
 }
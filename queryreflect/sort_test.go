@@ -1,6 +1,7 @@
 package queryreflect_test
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/royalcat/query"
@@ -82,3 +83,75 @@ func TestApplySort(t *testing.T) {
 		}, out)
 	}
 }
+
+func TestApplySortMultipleKeys(t *testing.T) {
+	t.Parallel()
+
+	type testStruct struct {
+		ID    uint    `json:"id"`
+		Group *string `json:"group"`
+	}
+
+	a, b := "a", "b"
+	require := require.New(t)
+	data := []testStruct{
+		{ID: 1, Group: &b},
+		{ID: 2, Group: &a},
+		{ID: 3, Group: nil},
+		{ID: 4, Group: &b},
+		{ID: 5, Group: &a},
+	}
+	s := query.Sort{
+		{Key: "group", Order: query.ASC},
+		{Key: "id", Order: query.DESC},
+	}
+
+	out, err := queryreflect.ApplySort(s, data)
+	require.NoError(err)
+	require.Equal([]testStruct{
+		{ID: 3, Group: nil},
+		{ID: 5, Group: &a},
+		{ID: 2, Group: &a},
+		{ID: 4, Group: &b},
+		{ID: 1, Group: &b},
+	}, out)
+}
+
+func TestApplySortNulls(t *testing.T) {
+	t.Parallel()
+
+	type task struct {
+		ID  int  `json:"id"`
+		Due *int `json:"due"`
+	}
+
+	require := require.New(t)
+	one, two := 1, 2
+	data := []task{{ID: 1, Due: &two}, {ID: 2, Due: nil}, {ID: 3, Due: &one}}
+	ids := func(ts []task) []int {
+		out := []int{}
+		for _, t := range ts {
+			out = append(out, t.ID)
+		}
+		return out
+	}
+
+	cases := []struct {
+		sort query.SortField
+		want []int
+	}{
+		{query.SortField{Key: "due", Order: query.ASC}, []int{2, 3, 1}},
+		{query.SortField{Key: "due", Order: query.DESC}, []int{1, 3, 2}},
+		{query.SortField{Key: "due", Order: query.ASC, Nulls: query.NullsLast}, []int{3, 1, 2}},
+		{query.SortField{Key: "due", Order: query.DESC, Nulls: query.NullsFirst}, []int{2, 1, 3}},
+	}
+	for _, c := range cases {
+		out, err := queryreflect.ApplySort(query.Sort{c.sort}, slices.Clone(data))
+		require.NoError(err)
+		require.Equal(c.want, ids(out), c.sort)
+	}
+
+	out, err := queryreflect.ApplySort(query.Sort{{Key: "due", Order: query.ASC}}, []task{})
+	require.NoError(err)
+	require.Empty(out)
+}
@@ -0,0 +1,49 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OperatorDef describes a custom operator, see RegisterOperator.
+type OperatorDef struct {
+	// Parse parses filter values for a field of type t, the element type for slices.
+	// values holds a single element unless List is set.
+	// Nil parses values like OperatorEqual or OperatorIn.
+	Parse func(t reflect.Type, values []string) (any, error)
+	// List makes the operator take a comma separated list like OperatorIn.
+	List bool
+	// Allowed reports whether the operator applies to a field of type t,
+	// the element type for slices. Nil allows any type.
+	Allowed func(t reflect.Type) bool
+}
+
+var operators syncmap[Operator, OperatorDef]
+
+// RegisterOperator adds a custom filter operator, it's accepted by the parser as
+// field{op}. Backends translate it with their own registrations, see
+// querymongo.RegisterOperator and queryreflect.RegisterOperator.
+// Usually called from init.
+func RegisterOperator(op Operator, def OperatorDef) error {
+	if op == OperatorDefault || isBuiltinOperator(op) || isGroupOperator(op) || op == OperatorElemMatch {
+		return fmt.Errorf("operator %q is builtin", op)
+	}
+	if _, loaded := operators.LoadOrStore(op, def); loaded {
+		return fmt.Errorf("operator %q is already registered", op)
+	}
+	return nil
+}
+
+// LookupOperator returns the definition of a registered custom operator.
+func LookupOperator(op Operator) (OperatorDef, bool) {
+	return operators.Load(op)
+}
+
+// isListOperator reports whether values of op are a list.
+func isListOperator(op Operator) bool {
+	if op == OperatorIn || op == OperatorNotIn || op == OperatorBetween || op == OperatorAll {
+		return true
+	}
+	def, ok := operators.Load(op)
+	return ok && def.List
+}
@@ -0,0 +1,55 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SearchField is a string field of the model Query.Search is matched against.
+type SearchField struct {
+	Path   string
+	Weight int
+}
+
+// ErrNoSearchFields is returned when Query.Search is applied to a model without search fields.
+var ErrNoSearchFields = errors.New("model has no search fields")
+
+// SearchFields lists fields marked with the `search` struct tag, the tag value is an optional weight:
+//
+//	Title string `json:"title" search:"10"`
+//	Body  string `json:"body" search:""`
+func SearchFields[Model any]() ([]SearchField, error) {
+	fields := []SearchField{}
+	err := walkFields(modelType[Model](), func(path string, field reflect.StructField) (bool, error) {
+		tag, ok := field.Tag.Lookup("search")
+		if !ok {
+			return true, nil
+		}
+		if elemType(field.Type).Kind() != reflect.String {
+			return false, fmt.Errorf("search field %s must be a string, got %s", path, field.Type.String())
+		}
+
+		weight := 1
+		if tag != "" {
+			w, err := strconv.Atoi(tag)
+			if err != nil || w < 1 {
+				return false, fmt.Errorf("invalid search weight of field %s: %s", path, tag)
+			}
+			weight = w
+		}
+		fields = append(fields, SearchField{Path: path, Weight: weight})
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// SearchTokens splits a search string into lower case words, every one of them must match.
+func SearchTokens(search string) []string {
+	return strings.Fields(strings.ToLower(search))
+}
@@ -1,5 +1,7 @@
 package query
 
+import "strings"
+
 type SortOrder int8
 
 const (
@@ -7,9 +9,74 @@ const (
 	DESC SortOrder = -1
 )
 
+// NullOrder places missing and nil values of a sort key regardless of the sort order.
+type NullOrder int8
+
+const (
+	// NullsDefault keeps the backend order, nulls sort before other values
+	// in ascending order in both queryreflect and querymongo.
+	NullsDefault NullOrder = iota
+	NullsFirst
+	NullsLast
+)
+
 type SortField struct {
 	Key   string
 	Order SortOrder
+	Nulls NullOrder
+}
+
+// NullsFirst returns a copy of the sort key with nulls placed first.
+func (f SortField) NullsFirst() SortField {
+	f.Nulls = NullsFirst
+	return f
+}
+
+// NullsLast returns a copy of the sort key with nulls placed last.
+func (f SortField) NullsLast() SortField {
+	f.Nulls = NullsLast
+	return f
+}
+
+// NullsAfter reports whether nulls are sorted after other values.
+func (f SortField) NullsAfter() bool {
+	return f.Nulls == NullsLast || f.Nulls == NullsDefault && f.Order == DESC
+}
+
+const (
+	nullsFirstSuffix = ":nullsfirst"
+	nullsLastSuffix  = ":nullslast"
+)
+
+// parseSortKey parses a key in the syntax of the sort parameter:
+// an optional "-" or "+" prefix and ":nullsfirst" or ":nullslast" suffix.
+func parseSortKey(key string) SortField {
+	f := SortField{Key: key, Order: ASC, Nulls: NullsDefault}
+	if k, ok := strings.CutPrefix(f.Key, "-"); ok {
+		f.Key, f.Order = k, DESC
+	} else if k, ok := strings.CutPrefix(f.Key, "+"); ok {
+		f.Key = k
+	}
+	if k, ok := strings.CutSuffix(f.Key, nullsFirstSuffix); ok {
+		f.Key, f.Nulls = k, NullsFirst
+	} else if k, ok := strings.CutSuffix(f.Key, nullsLastSuffix); ok {
+		f.Key, f.Nulls = k, NullsLast
+	}
+	return f
+}
+
+func sortKeyString(f SortField) string {
+	key := f.Key
+	if f.Order == DESC {
+		key = "-" + key
+	}
+	switch f.Nulls {
+	case NullsFirst:
+		key += nullsFirstSuffix
+	case NullsLast:
+		key += nullsLastSuffix
+	}
+	return key
 }
 
 type Sort []SortField
@@ -49,5 +116,16 @@ func (s *Sort) Set(key string, order SortOrder) {
 			return
 		}
 	}
-	*s = append(*s, SortField{Key: key, Order: order})
+	*s = append(*s, SortField{Key: key, Order: order, Nulls: NullsDefault})
+}
+
+// SetField is Set that also replaces the null order of the key.
+func (s *Sort) SetField(f SortField) {
+	for i := range *s {
+		if (*s)[i].Key == f.Key {
+			(*s)[i] = f
+			return
+		}
+	}
+	*s = append(*s, f)
 }
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+type orderItem struct {
+	Price int    `json:"price"`
+	Qty   int    `json:"qty"`
+	SKU   string `json:"sku"`
+}
+
+type orderModel struct {
+	ID     int         `json:"id"`
+	Items  []orderItem `json:"items"`
+	Tags   []string    `json:"tags"`
+	Scores []int       `json:"scores"`
+}
+
+func TestParseArrayOperators(t *testing.T) {
+	require := require.New(t)
+
+	f, err := query.ParseFilter[orderModel](url.Values{
+		"tags{all}":   {"a,b"},
+		"scores{all}": {"1", "2"},
+		"tags{size}":  {"2"},
+	})
+	require.NoError(err)
+	require.Equal(query.Filter{
+		{Field: "scores", Op: query.OperatorAll, Value: []int{1, 2}},
+		{Field: "tags", Op: query.OperatorAll, Value: []string{"a", "b"}},
+		{Field: "tags", Op: query.OperatorSize, Value: 2},
+	}, f)
+
+	_, err = query.ParseFilter[orderModel](url.Values{"id{size}": {"1"}}, query.WithCollectErrors())
+	require.ErrorIs(err, query.ErrOperatorNotAllowed)
+	_, err = query.ParseFilter[orderModel](url.Values{"tags{size}": {"-1"}})
+	require.ErrorIs(err, query.ErrInvalidValue)
+}
+
+func TestElemMatch(t *testing.T) {
+	require := require.New(t)
+
+	q, err := query.Build[orderModel](query.New().
+		ElemMatch("items", query.New().Where("price").Gte(10).Where("qty").Lt(5)).
+		ElemMatch("scores", query.New().Where("").Between(80, 85)),
+	)
+	require.NoError(err)
+	require.Equal([]string{"items.price", "items.qty", "scores", "scores"}, []string(q.Filter.Fields()))
+
+	data, err := q.MarshalJSON()
+	require.NoError(err)
+	require.Contains(string(data), `{"field":"items","op":"elemMatch","filter":[{"field":"price","op":"gte","value":"10"},{"field":"qty","op":"lt","value":"5"}]}`)
+	decoded, err := query.Decode[orderModel](data)
+	require.NoError(err)
+	require.Equal(q.Filter, decoded.Filter)
+
+	values, err := q.Values()
+	require.NoError(err)
+	require.Equal(url.Values{
+		"items{elemMatch}.price{gte}": {"10"},
+		"items{elemMatch}.qty{lt}":    {"5"},
+		"scores{elemMatch}{gte}":      {"80"},
+		"scores{elemMatch}{lte}":      {"85"},
+	}, values)
+	parsed, err := query.ParseQuery[orderModel](values)
+	require.NoError(err)
+	require.Equal(q.Filter, parsed.Filter)
+
+	_, err = query.New().ElemMatch("items", query.New().Or(
+		query.New().Where("price").Gte(10),
+		query.New().Where("qty").Lt(5),
+	)).Query().Values()
+	require.ErrorIs(err, query.ErrNotEncodable)
+
+	err = query.Validate[orderModel](query.Query{Filter: query.Filter{ //nolint:exhaustruct
+		query.ElemMatch("id", query.Filter{{Field: "", Op: query.OperatorEqual, Value: 1}}),
+	}})
+	require.ErrorIs(err, query.ErrOperatorNotAllowed)
+	err = query.Validate[orderModel](query.Query{Filter: query.Filter{ //nolint:exhaustruct
+		query.ElemMatch("items", query.Filter{{Field: "color", Op: query.OperatorEqual, Value: "red"}}),
+	}})
+	require.ErrorIs(err, query.ErrUnknownField)
+	err = query.Validate[orderModel](query.Query{Filter: query.Filter{ //nolint:exhaustruct
+		query.ElemMatch("items", query.Filter{
+			{Field: "", Op: query.OperatorIsNull, Value: false},
+			{Field: "price", Op: query.OperatorGreaterOrEqual, Value: 10},
+		}),
+	}})
+	require.ErrorIs(err, query.ErrInvalidValue)
+
+	canonical := query.Query{Filter: query.Filter{ //nolint:exhaustruct
+		query.ElemMatch("items", query.Filter{
+			{Field: "qty", Op: query.OperatorLess, Value: 5},
+			{Field: "price", Op: query.OperatorGreaterOrEqual, Value: 10},
+		}),
+	}}.Canonicalize()
+	require.Equal(q.Filter[0], canonical.Filter[0])
+}
+
+func TestParseElemMatch(t *testing.T) {
+	require := require.New(t)
+
+	// conditions on different keys of the same field match a single element
+	f, err := query.ParseFilter[orderModel](url.Values{
+		"items{elemMatch}.price{gte}": {"10"},
+		"items{elemMatch}.qty{lt}":    {"5"},
+		"items.sku":                   {"a"},
+		"scores{elemMatch}{gt}":       {"80"},
+		"scores{elemMatch}{lt}":       {"85"},
+	})
+	require.NoError(err)
+	require.Equal(query.Filter{
+		{Field: "items.sku", Op: query.OperatorDefault, Value: "a"},
+		query.ElemMatch("items", query.Filter{
+			{Field: "price", Op: query.OperatorGreaterOrEqual, Value: 10},
+			{Field: "qty", Op: query.OperatorLess, Value: 5},
+		}),
+		query.ElemMatch("scores", query.Filter{
+			{Field: "", Op: query.OperatorGreater, Value: 80},
+			{Field: "", Op: query.OperatorLess, Value: 85},
+		}),
+	}, f)
+
+	_, err = query.ParseFilter[orderModel](url.Values{"id{elemMatch}.price": {"1"}})
+	require.ErrorIs(err, query.ErrOperatorNotAllowed)
+	_, err = query.ParseFilter[orderModel](url.Values{"items{elemMatch}.color": {"red"}})
+	require.ErrorIs(err, query.ErrUnknownField)
+	_, err = query.ParseFilter[orderModel](url.Values{
+		"items{elemMatch}{isnull}": {"false"},
+		"items{elemMatch}.price":   {"1"},
+	})
+	require.ErrorIs(err, query.ErrInvalidValue)
+	_, err = query.ParseFilter[orderModel](url.Values{"items{elemMatch}price": {"1"}})
+	require.ErrorIs(err, query.ErrUnknownOperator)
+}
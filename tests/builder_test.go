@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	require := require.New(t)
+	q := query.New().
+		Where("name").Substr("x").
+		Where("age").Between(18, 65).
+		Where("tags").In("a", "b").
+		Or(
+			query.New().Where("age").Eq(1),
+			query.New().Where("name").Ne("y"),
+		).
+		OrderBy("-age", "name").
+		Page(2, 50).
+		Query()
+
+	require.Equal(query.Query{
+		Filter: query.Filter{
+			{Field: "name", Op: query.OperatorSubString, Value: "x"},
+			{Field: "age", Op: query.OperatorGreaterOrEqual, Value: 18},
+			{Field: "age", Op: query.OperatorLessOrEqual, Value: 65},
+			{Field: "tags", Op: query.OperatorIn, Value: []string{"a", "b"}},
+			query.Or(
+				query.Filter{{Field: "age", Op: query.OperatorEqual, Value: 1}},
+				query.Filter{{Field: "name", Op: query.OperatorNotEqual, Value: "y"}},
+			),
+		},
+		Sort: query.Sort{
+			{Key: "age", Order: query.DESC},
+			{Key: "name", Order: query.ASC},
+		},
+		Pagination: query.Pagination{Offset: 50, Limit: 50},
+	}, q)
+
+	_, err := query.Build[rangeModel](query.New().Where("name").Eq("x"))
+	require.ErrorIs(err, query.ErrUnknownField)
+
+	q, err = query.Build[rangeModel](query.New().Where("age").Gt(1).OrderBy("-age"))
+	require.NoError(err)
+	require.Len(q.Filter, 1)
+}
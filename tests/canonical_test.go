@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalize(t *testing.T) {
+	require := require.New(t)
+	moscow := time.FixedZone("MSK", 3*60*60)
+	a := query.Query{
+		Filter: query.Filter{
+			{Field: "name", Op: query.OperatorSubString, Value: "x"},
+			{Field: "id", Op: query.OperatorIn, Value: []id{10, 9, 10, 1}},
+			query.Or(
+				query.Filter{{Field: "ratio", Op: query.OperatorLess, Value: 0.5}},
+				query.Filter{{Field: "created_at", Op: query.OperatorGreater, Value: time.Date(2024, 5, 1, 3, 0, 0, 0, moscow)}},
+			),
+			{Field: "name", Op: query.OperatorSubString, Value: "x"},
+		},
+		Sort: query.Sort{
+			{Key: "id", Order: query.ASC},
+			{Key: "name", Order: query.ASC},
+			{Key: "id", Order: query.DESC},
+		},
+		Select: query.Fields{"name", "id", "name"},
+	}
+	b := query.Query{
+		Filter: query.Filter{
+			query.Or(
+				query.Filter{{Field: "created_at", Op: query.OperatorGreater, Value: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}},
+				query.Filter{{Field: "ratio", Op: query.OperatorLess, Value: 0.5}},
+			),
+			{Field: "id", Op: query.OperatorIn, Value: []id{1, 9, 10}},
+			{Field: "name", Op: query.OperatorSubString, Value: "x"},
+		},
+		Sort: query.Sort{
+			{Key: "id", Order: query.DESC},
+			{Key: "name", Order: query.ASC},
+		},
+		Select: query.Fields{"id", "name"},
+	}
+
+	require.Equal(b.Canonicalize(), a.Canonicalize())
+	require.Equal(query.Filter{
+		{Field: "id", Op: query.OperatorIn, Value: []id{1, 9, 10}},
+		{Field: "name", Op: query.OperatorSubString, Value: "x"},
+		query.Or(
+			query.Filter{{Field: "created_at", Op: query.OperatorGreater, Value: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}},
+			query.Filter{{Field: "ratio", Op: query.OperatorLess, Value: 0.5}},
+		),
+	}, a.Canonicalize().Filter)
+
+	hashA, err := a.Hash()
+	require.NoError(err)
+	hashB, err := b.Hash()
+	require.NoError(err)
+	require.Equal(hashA, hashB)
+	require.Len(hashA, 64)
+
+	b.Pagination.Limit = 10
+	hashC, err := b.Hash()
+	require.NoError(err)
+	require.NotEqual(hashA, hashC)
+}
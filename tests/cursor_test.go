@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+type cursorModel struct {
+	ID        id        `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Name      *string   `json:"name"`
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	require := require.New(t)
+	s := query.Sort{
+		{Key: "created_at", Order: query.DESC},
+		{Key: "name", Order: query.ASC},
+		{Key: "id", Order: query.ASC},
+	}
+	ts := time.Date(2024, 5, 1, 12, 30, 0, 123, time.UTC)
+	item := cursorModel{ID: 42, CreatedAt: ts, Name: nil}
+
+	c, err := query.NewCursor(item, s)
+	require.NoError(err)
+	require.Equal(query.Cursor{ts, nil, id(42)}, c)
+
+	key := []byte("secret")
+	token, err := query.EncodeCursor(c, s, key)
+	require.NoError(err)
+
+	decoded, err := query.DecodeCursor[cursorModel](token, s, key)
+	require.NoError(err)
+	require.Equal(c, decoded)
+
+	_, err = query.DecodeCursor[cursorModel](token, s, []byte("other"))
+	require.ErrorIs(err, query.ErrInvalidCursor)
+
+	_, err = query.DecodeCursor[cursorModel](token, s[:2], key)
+	require.ErrorIs(err, query.ErrInvalidCursor)
+
+	q, err := query.ParseQuery[cursorModel](url.Values{
+		"sort":   {"-created_at,name,id"},
+		"cursor": {token},
+		"limit":  {"10"},
+	}, query.WithCursorKey(key))
+	require.NoError(err)
+	require.Equal(c, q.Pagination.After)
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+type point struct {
+	X, Y int
+}
+
+func (*point) QueryUnmarshal(v string) (any, error) {
+	var p point
+	_, err := fmt.Sscanf(v, "%d:%d", &p.X, &p.Y)
+	return p, err
+}
+
+func (p point) QueryMarshal() (string, error) {
+	return fmt.Sprintf("%d:%d", p.X, p.Y), nil
+}
+
+type level struct {
+	name string
+}
+
+func (l *level) UnmarshalJSON(data []byte) error {
+	l.name = strings.ToUpper(strings.Trim(string(data), `"`))
+	return nil
+}
+
+func (l level) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + l.name + `"`), nil
+}
+
+type encodeModel struct {
+	ID        id        `json:"id"`
+	Name      string    `json:"name"`
+	Tags      []string  `json:"tags"`
+	Small     uint8     `json:"small"`
+	Ratio     float32   `json:"ratio"`
+	Score     float64   `json:"score"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	Location  point     `json:"location"`
+	Level     level     `json:"level"`
+	Nested    struct {
+		Count *int `json:"count"`
+	} `json:"nested"`
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	require := require.New(t)
+	q := query.Query{
+		Search: "hello world",
+		Filter: query.Filter{
+			{Field: "active", Op: query.OperatorEqual, Value: true},
+			{Field: "created_at", Op: query.OperatorGreaterOrEqual, Value: time.Date(2024, 5, 1, 10, 0, 0, 500, time.UTC)},
+			{Field: "id", Op: query.OperatorIn, Value: []id{1, 2, 3}},
+			{Field: "level", Op: query.OperatorDefault, Value: level{name: "HIGH"}},
+			{Field: "location", Op: query.OperatorNotEqual, Value: point{X: 1, Y: -2}},
+			{Field: "name", Op: query.OperatorSubString, Value: "a&b=c"},
+			{Field: "nested.count", Op: query.OperatorLess, Value: 10},
+			{Field: "ratio", Op: query.OperatorGreater, Value: float32(0.1)},
+			{Field: "score", Op: query.OperatorLessOrEqual, Value: 1e21},
+			{Field: "small", Op: query.OperatorDefault, Value: uint8(255)},
+			{Field: "tags", Op: query.OperatorDefault, Value: "x"},
+			{Field: "tags", Op: query.OperatorDefault, Value: "y"},
+			{Field: "tags", Op: query.OperatorIn, Value: []string{"a,b", `c\d`, ""}},
+		},
+		Sort: query.Sort{
+			{Key: "created_at", Order: query.DESC},
+			{Key: "id", Order: query.ASC},
+		},
+		Pagination: query.Pagination{
+			Offset: 10,
+			Limit:  20,
+			After:  query.Cursor{time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), id(7)},
+		},
+		Select: query.Fields{"id", "name"},
+	}
+
+	key := query.WithCursorKey([]byte("key"))
+	s, err := q.Encode(key)
+	require.NoError(err)
+
+	values, err := url.ParseQuery(s)
+	require.NoError(err)
+	parsed, err := query.ParseQuery[encodeModel](values, key)
+	require.NoError(err)
+	require.Equal(q, parsed)
+
+	again, err := parsed.Encode(key)
+	require.NoError(err)
+	require.Equal(s, again)
+}
+
+func TestEncode(t *testing.T) {
+	require := require.New(t)
+	q := query.Query{
+		Filter: query.Filter{
+			{Field: "name", Op: query.OperatorSubString, Value: "x"},
+			{Field: "id", Op: query.OperatorIn, Value: []id{1, 2}},
+		},
+		Sort:       query.Sort{{Key: "name", Order: query.DESC}},
+		Pagination: query.Pagination{Limit: 5},
+	}
+	require.Equal("id%7Bin%7D=1%2C2&limit=5&name%7Bsubstr%7D=x&sort=-name", q.String())
+
+	_, err := query.Query{Filter: query.Filter{query.Not(query.Filter{})}}.Encode()
+	require.ErrorIs(err, query.ErrNotEncodable)
+
+	_, err = query.Query{Filter: query.Filter{{Field: "limit", Value: 1}}}.Encode()
+	require.ErrorIs(err, query.ErrNotEncodable)
+}
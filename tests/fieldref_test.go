@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+type stockModel struct {
+	Name         string    `json:"name"`
+	Stock        int       `json:"stock"`
+	ReorderLevel int       `json:"reorder_level"`
+	Price        float64   `json:"price"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func TestParseFieldRef(t *testing.T) {
+	require := require.New(t)
+
+	v := url.Values{
+		"stock{lt}":      {"$reorder_level"},
+		"updated_at{gt}": {"$created_at"},
+		"name":           {"$$money"},
+		"name{in}":       {"$a,b"},
+	}
+	f, err := query.ParseFilter[stockModel](v)
+	require.NoError(err)
+	require.Equal(query.Filter{
+		{Field: "name", Op: query.OperatorDefault, Value: "$money"},
+		{Field: "name", Op: query.OperatorIn, Value: []string{"$a", "b"}},
+		{Field: "stock", Op: query.OperatorLess, Value: query.FieldRef("reorder_level")},
+		{Field: "updated_at", Op: query.OperatorGreater, Value: query.FieldRef("created_at")},
+	}, f)
+
+	q := query.Query{Filter: f} //nolint:exhaustruct
+	encoded, err := q.Values()
+	require.NoError(err)
+	require.Equal(v, encoded)
+
+	data, err := q.MarshalJSON()
+	require.NoError(err)
+	decoded, err := query.Decode[stockModel](data)
+	require.NoError(err)
+	require.Equal(f, decoded.Filter)
+
+	_, err = query.ParseFilter[stockModel](url.Values{"stock{lt}": {"$price"}})
+	require.ErrorIs(err, query.ErrInvalidValue)
+	_, err = query.ParseFilter[stockModel](url.Values{"stock{lt}": {"$missing"}})
+	require.ErrorIs(err, query.ErrUnknownField)
+	_, err = query.ParseFilter[stockModel](url.Values{"stock{lt}": {"$reorder_level"}}, query.WithPolicy(query.PolicyMap{
+		"stock": {Filter: true, Operators: nil, Sort: false},
+	}))
+	require.ErrorIs(err, query.ErrFieldNotAllowed)
+
+	err = query.Validate[stockModel](query.Query{Filter: query.Filter{ //nolint:exhaustruct
+		{Field: "stock", Op: query.OperatorIn, Value: query.FieldRef("reorder_level")},
+	}})
+	require.ErrorIs(err, query.ErrInvalidValue)
+	err = query.Validate[stockModel](query.Query{Filter: query.Filter{ //nolint:exhaustruct
+		{Field: "created_at", Op: query.OperatorLessOrEqual, Value: query.FieldRef("name")},
+	}})
+	require.ErrorIs(err, query.ErrInvalidValue)
+}
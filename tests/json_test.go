@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryJSON(t *testing.T) {
+	require := require.New(t)
+	q := query.Query{
+		Search: "text",
+		Filter: query.Filter{
+			{Field: "id", Op: query.OperatorIn, Value: []id{1, 9007199254740993}},
+			query.Or(
+				query.Filter{{Field: "created_at", Op: query.OperatorGreater, Value: time.Date(2024, 5, 1, 0, 0, 0, 1, time.UTC)}},
+				query.Filter{{Field: "location", Op: query.OperatorDefault, Value: point{X: 1, Y: 2}}},
+			),
+			{Field: "ratio", Op: query.OperatorLess, Value: float32(0.5)},
+		},
+		Sort:       query.Sort{{Key: "id", Order: query.DESC}},
+		Pagination: query.Pagination{Offset: 1, Limit: 2, After: query.Cursor{id(5)}},
+		Select:     query.Fields{"name"},
+	}
+
+	data, err := json.Marshal(q)
+	require.NoError(err)
+	require.JSONEq(`{
+		"v": 1,
+		"search": "text",
+		"filter": [
+			{"field": "id", "op": "in", "values": ["1", "9007199254740993"]},
+			{"op": "or", "groups": [
+				[{"field": "created_at", "op": "gt", "value": "2024-05-01T00:00:00.000000001Z"}],
+				[{"field": "location", "value": "1:2"}]
+			]},
+			{"field": "ratio", "op": "lt", "value": "0.5"}
+		],
+		"sort": [{"key": "id", "order": "desc"}],
+		"select": ["name"],
+		"offset": 1,
+		"limit": 2,
+		"after": ["5"]
+	}`, string(data))
+
+	decoded, err := query.Decode[encodeModel](data)
+	require.NoError(err)
+	require.Equal(q, decoded)
+
+	var raw query.Query
+	require.NoError(json.Unmarshal(data, &raw))
+	require.Equal([]string{"1", "9007199254740993"}, raw.Filter[0].Value)
+
+	_, err = query.Decode[encodeModel]([]byte(`{"v": 2}`))
+	require.Error(err)
+
+	_, err = query.Decode[encodeModel]([]byte(`{"v": 1, "filter": [{"field": "id", "value": "x"}]}`))
+	require.ErrorIs(err, query.ErrInvalidValue)
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimits(t *testing.T) {
+	require := require.New(t)
+
+	limits := query.WithLimits(query.Limits{MaxFilters: 2, MaxValues: 3, MaxDepth: 1, MaxPattern: 5, MaxSortKeys: 1})
+
+	_, err := query.ParseQuery[model](url.Values{
+		"id{in}":       {"1,2,3"},
+		"name{substr}": {"abcde"},
+		"sort":         {"id"},
+	}, limits)
+	require.NoError(err)
+
+	cases := []struct {
+		values url.Values
+		limit  string
+		field  string
+	}{
+		{url.Values{"id": {"1", "2"}, "name": {"x"}}, "MaxFilters", ""},
+		{url.Values{"id{in}": {"1,2", "3,4"}}, "MaxValues", "id"},
+		{url.Values{"nested.based": {"true"}}, "MaxDepth", "nested.based"},
+		{url.Values{"name{regex}": {"(a+)+$"}}, "MaxPattern", "name"},
+		{url.Values{"search": {"(a+)+$"}}, "MaxPattern", "search"},
+		{url.Values{"sort": {"id,-name"}}, "MaxSortKeys", ""},
+		{url.Values{"sort": {"nested.based"}}, "MaxDepth", "nested.based"},
+		{url.Values{"select": {"nested.based"}}, "MaxDepth", "nested.based"},
+	}
+	for _, c := range cases {
+		_, err := query.ParseQuery[model](c.values, limits)
+		require.ErrorIs(err, query.ErrLimitExceeded, c.limit)
+
+		var limitErr *query.LimitError
+		require.True(errors.As(err, &limitErr), c.limit)
+		require.Equal(c.limit, limitErr.Limit)
+
+		var fieldErr *query.FieldError
+		require.True(errors.As(err, &fieldErr))
+		require.Equal(c.field, fieldErr.Field)
+	}
+
+	_, err = query.ParseStringFilter[model](map[string]string{"id{in}": strings.Repeat("1,", 100) + "1"}, limits)
+	var limitErr *query.LimitError
+	require.True(errors.As(err, &limitErr))
+	require.Equal(query.LimitError{Limit: "MaxValues", Max: 3, Got: 101}, *limitErr)
+	require.EqualError(err, `query limit exceeded "id" operator "in": MaxValues is 3, got 101`)
+
+	// repeated keys of a non-list operator are separate filters in both paths
+	valuesLimit := query.Limits{MaxFilters: 0, MaxValues: 2, MaxDepth: 0, MaxPattern: 0, MaxSortKeys: 0}
+	q, err := query.ParseQuery[model](url.Values{"id": {"1", "2", "3"}}, query.WithLimits(valuesLimit))
+	require.NoError(err)
+	require.Len(q.Filter, 3)
+	require.NoError(valuesLimit.Check(q))
+}
+
+func TestValidateLimits(t *testing.T) {
+	require := require.New(t)
+
+	limits := query.Limits{MaxFilters: 3, MaxValues: 2, MaxDepth: 0, MaxPattern: 0, MaxSortKeys: 0}
+
+	b := query.New().Where("id").In(id(1), id(2)).Or(
+		query.New().Where("name").Eq("a"),
+		query.New().Where("name").Eq("b"),
+	)
+	_, err := query.Build[model](b, query.WithLimits(limits))
+	require.ErrorIs(err, query.ErrLimitExceeded)
+
+	q := query.New().Where("id").In(id(1), id(2), id(3)).Query()
+	var limitErr *query.LimitError
+	require.True(errors.As(limits.Check(q), &limitErr))
+	require.Equal("MaxValues", limitErr.Limit)
+
+	require.NoError(limits.Check(query.New().Where("id").In(id(1), id(2)).Query()))
+	require.NoError(query.Limits{}.Check(q))
+
+	patternLimit := query.Limits{MaxFilters: 0, MaxValues: 0, MaxDepth: 0, MaxPattern: 5, MaxSortKeys: 0}
+	search := query.Query{Search: "(a+)+$"} //nolint:exhaustruct
+	require.True(errors.As(patternLimit.Check(search), &limitErr))
+	require.Equal("MaxPattern", limitErr.Limit)
+	require.ErrorIs(query.Validate[model](search, query.WithLimits(patternLimit)), query.ErrLimitExceeded)
+
+	data, err := json.Marshal(q)
+	require.NoError(err)
+	_, err = query.Decode[model](data, query.WithLimits(limits))
+	require.ErrorIs(err, query.ErrLimitExceeded)
+}
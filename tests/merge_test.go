@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryScope(t *testing.T) {
+	require := require.New(t)
+	user := query.Query{
+		Filter: query.Filter{
+			{Field: "tenant_id", Op: query.OperatorEqual, Value: 2},
+			{Field: "deleted", Op: query.OperatorDefault, Value: false},
+			{Field: "name", Op: query.OperatorSubString, Value: "x"},
+		},
+		Sort:       query.Sort{{Key: "name", Order: query.ASC}},
+		Pagination: query.Pagination{Offset: 10, Limit: 1000},
+		Select:     query.Fields{"id", "secret", "name"},
+	}
+	scope := query.Query{
+		Filter: query.Filter{
+			{Field: "tenant_id", Op: query.OperatorDefault, Value: 1},
+			{Field: "deleted", Op: query.OperatorEqual, Value: false},
+		},
+		Sort:       query.Sort{{Key: "id", Order: query.ASC}, {Key: "name", Order: query.DESC}},
+		Pagination: query.Pagination{Limit: 100},
+		Select:     query.Fields{"id", "name"},
+	}
+
+	q, conflicts := user.Scope(scope)
+	require.Equal(query.Query{
+		Filter: query.Filter{
+			{Field: "name", Op: query.OperatorSubString, Value: "x"},
+			{Field: "tenant_id", Op: query.OperatorDefault, Value: 1},
+			{Field: "deleted", Op: query.OperatorEqual, Value: false},
+		},
+		Sort:       query.Sort{{Key: "name", Order: query.ASC}, {Key: "id", Order: query.ASC}},
+		Pagination: query.Pagination{Offset: 10, Limit: 100},
+		Select:     query.Fields{"id", "name"},
+	}, q)
+	require.Equal([]query.Conflict{{
+		Scope:   query.FieldFilter{Field: "tenant_id", Op: query.OperatorDefault, Value: 1},
+		Dropped: query.FieldFilter{Field: "tenant_id", Op: query.OperatorEqual, Value: 2},
+	}}, conflicts)
+
+	q, conflicts = query.Query{}.Scope(scope)
+	require.Empty(conflicts)
+	require.Equal(uint64(100), q.Pagination.Limit)
+	require.Equal(scope.Filter, q.Filter)
+}
+
+func TestQueryMerge(t *testing.T) {
+	require := require.New(t)
+	a := query.Query{
+		Search:     "a",
+		Filter:     query.Filter{{Field: "id", Value: 1}},
+		Sort:       query.Sort{{Key: "id", Order: query.DESC}},
+		Pagination: query.Pagination{Offset: 5, Limit: 10},
+	}
+	b := query.Query{
+		Filter:     query.Filter{{Field: "name", Value: "x"}},
+		Sort:       query.Sort{{Key: "id", Order: query.ASC}, {Key: "name", Order: query.ASC}},
+		Pagination: query.Pagination{Limit: 20},
+	}
+	require.Equal(query.Query{
+		Search:     "a",
+		Filter:     query.Filter{{Field: "id", Value: 1}, {Field: "name", Value: "x"}},
+		Sort:       query.Sort{{Key: "id", Order: query.DESC}, {Key: "name", Order: query.ASC}},
+		Pagination: query.Pagination{Offset: 5, Limit: 20},
+	}, a.Merge(b))
+
+	a.Pagination.After = query.Cursor{7}
+	require.Equal(a.Sort, a.Merge(b).Sort)
+}
+
+func TestQueryScopeSelect(t *testing.T) {
+	require := require.New(t)
+
+	scope := query.Query{Select: query.Fields{"id", "nested.based", "profile"}} //nolint:exhaustruct
+	cases := []struct {
+		user query.Fields
+		want query.Fields
+	}{
+		{query.Fields{"secret"}, query.Fields{"id", "nested.based", "profile"}},
+		{query.Fields{"nested"}, query.Fields{"nested.based"}},
+		{query.Fields{"profile.age", "secret", "id"}, query.Fields{"profile.age", "id"}},
+		{nil, query.Fields{"id", "nested.based", "profile"}},
+	}
+	for _, c := range cases {
+		q, _ := query.Query{Select: c.user}.Scope(scope) //nolint:exhaustruct
+		require.Equal(c.want, q.Select, c.user)
+	}
+}
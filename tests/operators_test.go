@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+type operatorsModel struct {
+	Name    string    `json:"name"`
+	Age     int       `json:"age"`
+	Tags    []string  `json:"tags"`
+	Created time.Time `json:"created_at"`
+	Parent  *int      `json:"parent"`
+}
+
+func TestParseExtendedOperators(t *testing.T) {
+	require := require.New(t)
+
+	v := url.Values{
+		"age{nin}":            {"1,2", "3"},
+		"age{between}":        {"18,65"},
+		"created_at{between}": {"2024-01-01T00:00:00Z,2024-02-01T00:00:00Z"},
+		"name{prefix}":        {"Jo"},
+		"name{suffix}":        {"hn"},
+		"name{regex}":         {"^J[a-z]+$"},
+		"parent{isnull}":      {"true"},
+		"tags{exists}":        {"false"},
+	}
+	f, err := query.ParseFilter[operatorsModel](v)
+	require.NoError(err)
+	require.Equal(query.Filter{
+		{Field: "age", Op: query.OperatorBetween, Value: []int{18, 65}},
+		{Field: "age", Op: query.OperatorNotIn, Value: []int{1, 2, 3}},
+		{Field: "created_at", Op: query.OperatorBetween, Value: []time.Time{
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		}},
+		{Field: "name", Op: query.OperatorPrefix, Value: "Jo"},
+		{Field: "name", Op: query.OperatorRegex, Value: "^J[a-z]+$"},
+		{Field: "name", Op: query.OperatorSuffix, Value: "hn"},
+		{Field: "parent", Op: query.OperatorIsNull, Value: true},
+		{Field: "tags", Op: query.OperatorExists, Value: false},
+	}, f)
+
+	q := query.Query{Filter: f} //nolint:exhaustruct
+	encoded, err := q.Values()
+	require.NoError(err)
+	require.Equal("1,2,3", encoded.Get("age{nin}"))
+	require.Equal("18,65", encoded.Get("age{between}"))
+	parsed, err := query.ParseFilter[operatorsModel](encoded)
+	require.NoError(err)
+	require.Equal(f, parsed)
+
+	data, err := q.MarshalJSON()
+	require.NoError(err)
+	decoded, err := query.Decode[operatorsModel](data)
+	require.NoError(err)
+	require.Equal(f, decoded.Filter)
+
+	require.Equal([]int{1, 2, 3}, query.Query{Filter: query.Filter{ //nolint:exhaustruct
+		{Field: "age", Op: query.OperatorNotIn, Value: []int{3, 1, 2, 1}},
+	}}.Canonicalize().Filter[0].Value)
+}
+
+func TestParseExtendedOperatorsErrors(t *testing.T) {
+	require := require.New(t)
+
+	_, err := query.ParseFilter[operatorsModel](url.Values{
+		"age{between}":  {"1,2,3"},
+		"name{regex}":   {"("},
+		"age{prefix}":   {"1"},
+		"tags{isnull}":  {"maybe"},
+		"parent{regex}": {"x"},
+	}, query.WithCollectErrors())
+	var errs query.Errors
+	require.ErrorAs(err, &errs)
+	require.Len(errs, 5)
+	require.ErrorIs(errs[0], query.ErrInvalidValue)
+	require.Equal("age", errs[0].Field)
+	require.ErrorIs(errs[1], query.ErrOperatorNotAllowed)
+	require.Equal(query.OperatorPrefix, errs[1].Op)
+	require.ErrorIs(errs[2], query.ErrInvalidValue)
+	require.Equal("name", errs[2].Field)
+	require.ErrorIs(errs[3], query.ErrOperatorNotAllowed)
+	require.Equal("parent", errs[3].Field)
+	require.ErrorIs(errs[4], query.ErrInvalidValue)
+	require.Equal("tags", errs[4].Field)
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPage(t *testing.T) {
+	require := require.New(t)
+
+	type item struct {
+		ID int `json:"id"`
+	}
+	q := query.Query{ //nolint:exhaustruct
+		Sort:       query.Sort{{Key: "id", Order: query.ASC}},
+		Pagination: query.Pagination{Offset: 0, Limit: 2, After: query.Cursor{1}},
+	}
+
+	page, err := query.NewPage(q, []item{{ID: 2}, {ID: 3}}, 5, true)
+	require.NoError(err)
+	require.Equal(query.Cursor{3}, page.Next)
+
+	data, err := json.Marshal(page)
+	require.NoError(err)
+	require.JSONEq(`{"items": [{"id": 2}, {"id": 3}], "total": 5, "offset": 0, "limit": 2, "has_next": true}`, string(data))
+
+	next, ok := page.NextQuery(q)
+	require.True(ok)
+	require.Equal(query.Cursor{3}, next.Pagination.After)
+	require.Equal(query.Cursor{1}, q.Pagination.After)
+
+	key := []byte("secret")
+	linked, err := page.WithNextLink(q, query.WithCursorKey(key))
+	require.NoError(err)
+	data, err = json.Marshal(linked)
+	require.NoError(err)
+	var envelope struct {
+		Next string `json:"next"`
+	}
+	require.NoError(json.Unmarshal(data, &envelope))
+	require.Equal(linked.NextLink, envelope.Next)
+	values, err := url.ParseQuery(envelope.Next)
+	require.NoError(err)
+	parsed, err := query.ParseQuery[item](values, query.WithCursorKey(key))
+	require.NoError(err)
+	require.Equal(query.Cursor{3}, parsed.Pagination.After)
+
+	q.Pagination.After = nil
+	page, err = query.NewPage(q, []item{{ID: 1}, {ID: 2}}, 5, true)
+	require.NoError(err)
+	next, ok = page.NextQuery(q)
+	require.True(ok)
+	require.Equal(uint64(2), next.Pagination.Offset)
+	require.Equal("limit=2&offset=2&sort=id", next.String())
+
+	empty, err := query.NewPage[item](q, nil, 0, false)
+	require.NoError(err)
+	require.Equal([]item{}, empty.Items)
+	_, ok = empty.NextQuery(q)
+	require.False(ok)
+	empty, err = empty.WithNextLink(q)
+	require.NoError(err)
+	require.Empty(empty.NextLink)
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePagination(t *testing.T) {
+	require := require.New(t)
+
+	policy := query.PaginationPolicy{DefaultLimit: 20, MaxLimit: 100, MaxOffset: 1000}
+	cases := []struct {
+		in    query.Pagination
+		want  query.Pagination
+		clamp bool
+	}{
+		{
+			query.Pagination{Offset: 10, Limit: 50},
+			query.Pagination{Offset: 10, Limit: 50},
+			false,
+		},
+		{
+			query.Pagination{},
+			query.Pagination{Limit: 20, Clamp: query.PaginationClamp{DefaultLimit: true}},
+			true,
+		},
+		{
+			query.Pagination{Offset: 5000, Limit: 500},
+			query.Pagination{Offset: 1000, Limit: 100, Clamp: query.PaginationClamp{Limit: true, Offset: true}},
+			true,
+		},
+	}
+	for _, c := range cases {
+		q := query.Query{Pagination: c.in}.Normalize(policy) //nolint:exhaustruct
+		require.Equal(c.want, q.Pagination)
+		require.Equal(c.clamp, q.Pagination.Clamp.Clamped())
+	}
+
+	q := query.Query{}.Normalize(query.PaginationPolicy{MaxLimit: 100}) //nolint:exhaustruct
+	require.Equal(query.Pagination{Limit: 100, Clamp: query.PaginationClamp{Limit: true}}, q.Pagination)
+
+	q = query.Query{}.Normalize(query.PaginationPolicy{}) //nolint:exhaustruct
+	require.Equal(query.Pagination{}, q.Pagination)
+}
+
+func TestParsePaginationPolicy(t *testing.T) {
+	require := require.New(t)
+
+	policy := query.WithPaginationPolicy(query.PaginationPolicy{DefaultLimit: 10, MaxLimit: 50, MaxOffset: 0})
+
+	q, err := query.ParseQuery[model](url.Values{"limit": {"1000"}, "offset": {"30"}}, policy)
+	require.NoError(err)
+	require.Equal(query.Pagination{Offset: 30, Limit: 50, Clamp: query.PaginationClamp{Limit: true}}, q.Pagination)
+
+	encoded, err := q.Values()
+	require.NoError(err)
+	require.Equal(url.Values{"limit": {"50"}, "offset": {"30"}}, encoded)
+
+	q, err = query.ParseQuery[model](url.Values{}, policy)
+	require.NoError(err)
+	require.Equal(uint64(10), q.Pagination.Limit)
+	require.True(q.Pagination.Clamp.DefaultLimit)
+
+	data, err := q.MarshalJSON()
+	require.NoError(err)
+	decoded, err := query.Decode[model]([]byte(`{"v": 1, "limit": 70}`), policy)
+	require.NoError(err)
+	require.Equal(uint64(50), decoded.Pagination.Limit)
+	require.JSONEq(`{"v": 1, "limit": 10}`, string(data))
+
+	q, err = query.ParseQuery[model](url.Values{"limit": {"1000"}})
+	require.NoError(err)
+	require.Equal(uint64(1000), q.Pagination.Limit)
+}
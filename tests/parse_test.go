@@ -1,6 +1,8 @@
 package tests
 
 import (
+	"encoding/json"
+	"net/url"
 	"testing"
 
 	"github.com/royalcat/query"
@@ -34,3 +36,180 @@ func TestParseStringFilter(t *testing.T) {
 		{Field: "nested.based", Op: query.OperatorEqual, Value: true},
 	}, f)
 }
+
+func TestFilterGroupFields(t *testing.T) {
+	require := require.New(t)
+	f := query.Filter{
+		{Field: "name", Op: query.OperatorSubString, Value: "x"},
+		query.Or(
+			query.Filter{{Field: "id", Op: query.OperatorEqual, Value: 1}},
+			query.Filter{query.Not(query.Filter{{Field: "nested.based", Op: query.OperatorEqual, Value: true}})},
+		),
+	}
+	require.Equal(query.Fields{"name", "id", "nested.based"}, f.Fields())
+	require.Equal(map[string]query.Operator{
+		"name":         query.OperatorSubString,
+		"id":           query.OperatorEqual,
+		"nested.based": query.OperatorEqual,
+	}, f.Operators())
+}
+
+func TestParseQuery(t *testing.T) {
+	require := require.New(t)
+	v := url.Values{
+		"name{substr}": {"Primagen"},
+		"search":       {"hello world"},
+		"sort":         {"-id,nested.based"},
+		"offset":       {"20"},
+		"limit":        {"10"},
+	}
+	q, err := query.ParseQuery[model](v)
+	require.NoError(err)
+	require.Equal(query.Query{
+		Search: "hello world",
+		Filter: query.Filter{
+			{Field: "name", Op: query.OperatorSubString, Value: "Primagen"},
+		},
+		Sort: query.Sort{
+			{Key: "id", Order: query.DESC},
+			{Key: "nested.based", Order: query.ASC},
+		},
+		Pagination: query.Pagination{Offset: 20, Limit: 10},
+	}, q)
+
+	_, err = query.ParseQuery[model](url.Values{"sort": {"unknown"}})
+	require.Error(err)
+
+	_, err = query.ParseQuery[model](url.Values{"limit": {"-1"}})
+	require.Error(err)
+}
+
+func TestParseQueryParamNames(t *testing.T) {
+	require := require.New(t)
+	params := query.DefaultParamNames
+	params.Sort = "$sort"
+	v := url.Values{
+		"name":  {"sort"},
+		"$sort": {"name"},
+	}
+	q, err := query.ParseQuery[model](v, query.WithParamNames(params))
+	require.NoError(err)
+	require.Equal(query.Filter{{Field: "name", Op: query.OperatorDefault, Value: "sort"}}, q.Filter)
+	require.Equal(query.Sort{{Key: "name", Order: query.ASC}}, q.Sort)
+}
+
+type rangeModel struct {
+	Age  int      `json:"age"`
+	Tags []string `json:"tags"`
+}
+
+func TestParseFilterRepeated(t *testing.T) {
+	require := require.New(t)
+	v := url.Values{
+		"age{gte}": {"18"},
+		"age{lte}": {"65"},
+		"tags":     {"a", "b"},
+		"tags{in}": {"x,y", "z"},
+	}
+	f, err := query.ParseFilter[rangeModel](v)
+	require.NoError(err)
+	require.Equal(query.Filter{
+		{Field: "age", Op: query.OperatorGreaterOrEqual, Value: 18},
+		{Field: "age", Op: query.OperatorLessOrEqual, Value: 65},
+		{Field: "tags", Op: query.OperatorDefault, Value: "a"},
+		{Field: "tags", Op: query.OperatorDefault, Value: "b"},
+		{Field: "tags", Op: query.OperatorIn, Value: []string{"x", "y", "z"}},
+	}, f)
+
+	require.Equal(map[string][]query.Operator{
+		"age":  {query.OperatorGreaterOrEqual, query.OperatorLessOrEqual},
+		"tags": {query.OperatorDefault, query.OperatorDefault, query.OperatorIn},
+	}, f.FieldOperators())
+	require.Equal(query.Filter{
+		{Field: "age", Op: query.OperatorGreaterOrEqual, Value: 18},
+		{Field: "age", Op: query.OperatorLessOrEqual, Value: 65},
+	}, f.ByField("age"))
+}
+
+func TestParseErrors(t *testing.T) {
+	require := require.New(t)
+	v := url.Values{
+		"unknown":          {"1"},
+		"id{eq}":           {"abc"},
+		"name{like}":       {"x"},
+		"nested.based{gt}": {"true"},
+		"limit":            {"ten"},
+	}
+
+	_, err := query.ParseQuery[model](v)
+	var fieldErr *query.FieldError
+	require.ErrorAs(err, &fieldErr)
+	require.ErrorIs(err, query.ErrInvalidValue)
+	require.Equal("id", fieldErr.Field)
+	require.Equal(query.OperatorEqual, fieldErr.Op)
+	require.Equal("abc", fieldErr.Value)
+
+	_, err = query.ParseQuery[model](v, query.WithCollectErrors())
+	var errs query.Errors
+	require.ErrorAs(err, &errs)
+	require.Len(errs, 5)
+	require.ErrorIs(errs[0], query.ErrInvalidValue)
+	require.ErrorIs(errs[1], query.ErrUnknownOperator)
+	require.Equal("name", errs[1].Field)
+	require.ErrorIs(errs[2], query.ErrOperatorNotAllowed)
+	require.Equal("nested.based", errs[2].Field)
+	require.ErrorIs(errs[3], query.ErrUnknownField)
+	require.Equal("unknown", errs[3].Field)
+	require.ErrorIs(errs[4], query.ErrInvalidValue)
+	require.Equal("limit", errs[4].Field)
+
+	data, err := json.Marshal(errs[3])
+	require.NoError(err)
+	require.JSONEq(`{"error":"unknown field","field":"unknown","message":"unknown field \"unknown\": invalid path part: unknown"}`, string(data))
+}
+
+func TestParseQuerySelect(t *testing.T) {
+	require := require.New(t)
+	q, err := query.ParseQuery[model](url.Values{"select": {"name,nested.based", "name"}})
+	require.NoError(err)
+	require.Equal(query.Fields{"name", "nested.based"}, q.Select)
+
+	_, err = query.ParseQuery[model](url.Values{"select": {"name,secret"}})
+	require.ErrorIs(err, query.ErrUnknownField)
+
+	require.ErrorIs(query.Validate[model](query.Query{Select: query.Fields{"nested.x"}}), query.ErrUnknownField)
+}
+
+func TestParseSortNulls(t *testing.T) {
+	require := require.New(t)
+
+	s, err := query.ParseSort[model]("-nested.based:nullslast,id:nullsfirst")
+	require.NoError(err)
+	want := query.Sort{
+		{Key: "nested.based", Order: query.DESC, Nulls: query.NullsLast},
+		{Key: "id", Order: query.ASC, Nulls: query.NullsFirst},
+	}
+	require.Equal(want, s)
+
+	q := query.Query{Sort: s} //nolint:exhaustruct
+	encoded, err := q.Values()
+	require.NoError(err)
+	require.Equal("-nested.based:nullslast,id:nullsfirst", encoded.Get("sort"))
+
+	data, err := q.MarshalJSON()
+	require.NoError(err)
+	require.JSONEq(`{"v": 1, "sort": [
+		{"key": "nested.based", "order": "desc", "nulls": "last"},
+		{"key": "id", "order": "asc", "nulls": "first"}
+	]}`, string(data))
+	decoded, err := query.Decode[model](data)
+	require.NoError(err)
+	require.Equal(want, decoded.Sort)
+
+	built, err := query.Build[model](query.New().OrderBy("-nested.based:nullslast", "id:nullsfirst"))
+	require.NoError(err)
+	require.Equal(want, built.Sort)
+
+	_, err = query.ParseSort[model]("id:nullsmiddle")
+	require.Error(err)
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+type policyModel struct {
+	ID       int            `json:"id" query:"filter=eq,in;sort"`
+	Name     string         `json:"name,omitempty" query:"filter"`
+	Secret   string         `json:"secret"`
+	Profile  policyProfile  `json:"profile"`
+	Internal policyInternal `json:"internal" query:"-"`
+}
+
+type policyProfile struct {
+	Age int `json:"age" query:"filter=gte,lte;sort"`
+}
+
+type policyInternal struct {
+	Score int `json:"score" query:"filter"`
+}
+
+func TestTagPolicy(t *testing.T) {
+	require := require.New(t)
+
+	p, err := query.TagPolicy[policyModel]()
+	require.NoError(err)
+	require.Equal(query.PolicyMap{
+		"id":          {Filter: true, Operators: []query.Operator{query.OperatorEqual, query.OperatorIn}, Sort: true},
+		"name":        {Filter: true, Operators: nil, Sort: false},
+		"profile.age": {Filter: true, Operators: []query.Operator{query.OperatorGreaterOrEqual, query.OperatorLessOrEqual}, Sort: true},
+	}, p)
+
+	q, err := query.ParseQuery[policyModel](url.Values{
+		"id":               {"1"},
+		"name{substr}":     {"x"},
+		"profile.age{gte}": {"18"},
+		"sort":             {"-profile.age"},
+	}, query.WithPolicy(p))
+	require.NoError(err)
+	require.Len(q.Filter, 3)
+
+	_, err = query.ParseQuery[policyModel](url.Values{
+		"id{gt}":         {"1"},
+		"secret":         {"x"},
+		"internal.score": {"1"},
+		"sort":           {"name"},
+	}, query.WithPolicy(p), query.WithCollectErrors())
+	var errs query.Errors
+	require.ErrorAs(err, &errs)
+	require.Len(errs, 4)
+	require.ErrorIs(errs[0], query.ErrOperatorNotAllowed)
+	require.ErrorIs(errs[1], query.ErrFieldNotAllowed)
+	require.ErrorIs(errs[2], query.ErrFieldNotAllowed)
+	require.ErrorIs(errs[3], query.ErrSortNotAllowed)
+}
+
+func TestValidatePolicy(t *testing.T) {
+	require := require.New(t)
+
+	p, err := query.TagPolicy[policyModel]()
+	require.NoError(err)
+
+	err = query.Validate[policyModel](query.Query{
+		Filter: query.Filter{
+			query.Or(
+				query.Filter{{Field: "id", Op: query.OperatorEqual, Value: 1}},
+				query.Filter{{Field: "secret", Op: query.OperatorEqual, Value: "x"}},
+			),
+		},
+	}, query.WithPolicy(p))
+	require.ErrorIs(err, query.ErrFieldNotAllowed)
+
+	err = query.Validate[policyModel](query.Query{
+		Filter: query.Filter{{Field: "id", Op: "like", Value: 1}},
+	})
+	require.ErrorIs(err, query.ErrUnknownOperator)
+
+	err = query.Validate[policyModel](query.Query{
+		Filter: query.Filter{{Field: "secret", Op: query.OperatorEqual, Value: "x"}},
+		Sort:   query.Sort{{Key: "id", Order: query.ASC}},
+	})
+	require.NoError(err)
+}
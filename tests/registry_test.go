@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	operatorStartsWith query.Operator = "startswith"
+	operatorOverlaps   query.Operator = "overlaps"
+)
+
+func init() {
+	err := query.RegisterOperator(operatorStartsWith, query.OperatorDef{
+		Parse: func(t reflect.Type, values []string) (any, error) {
+			return strings.ToLower(values[0]), nil
+		},
+		List: false,
+		Allowed: func(t reflect.Type) bool {
+			return t.Kind() == reflect.String
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	err = query.RegisterOperator(operatorOverlaps, query.OperatorDef{Parse: nil, List: true, Allowed: nil})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterOperator(t *testing.T) {
+	require := require.New(t)
+
+	require.Error(query.RegisterOperator(query.OperatorEqual, query.OperatorDef{Parse: nil, List: false, Allowed: nil}))
+	require.Error(query.RegisterOperator(operatorStartsWith, query.OperatorDef{Parse: nil, List: false, Allowed: nil}))
+
+	f, err := query.ParseFilter[rangeModel](map[string][]string{
+		"tags{startswith}": {"AB"},
+		"tags{overlaps}":   {"a,b"},
+	})
+	require.NoError(err)
+	require.Equal(query.Filter{
+		{Field: "tags", Op: operatorOverlaps, Value: []string{"a", "b"}},
+		{Field: "tags", Op: operatorStartsWith, Value: "ab"},
+	}, f)
+
+	v, err := query.Query{Filter: f}.Values() //nolint:exhaustruct
+	require.NoError(err)
+	require.Equal("a,b", v.Get("tags{overlaps}"))
+
+	data, err := query.Query{Filter: f}.MarshalJSON() //nolint:exhaustruct
+	require.NoError(err)
+	q, err := query.Decode[rangeModel](data)
+	require.NoError(err)
+	require.Equal(f, q.Filter)
+
+	_, err = query.ParseFilter[rangeModel](map[string][]string{"age{startswith}": {"1"}})
+	require.ErrorIs(err, query.ErrOperatorNotAllowed)
+	_, err = query.ParseFilter[rangeModel](map[string][]string{"age{endswith}": {"1"}})
+	require.ErrorIs(err, query.ErrUnknownOperator)
+}
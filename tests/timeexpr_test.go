@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeExpr(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Date(2024, 5, 15, 13, 30, 0, 0, time.UTC) // Wednesday
+	clock := query.WithClock(func() time.Time { return now })
+
+	cases := map[string]time.Time{
+		"now":                  now,
+		"now-7d":               now.AddDate(0, 0, -7),
+		"now+1h-30m":           now.Add(30 * time.Minute),
+		"now/M":                time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		"startOfDay":           time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC),
+		"startOfWeek":          time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC),
+		"startOfMonth-1M":      time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		"startOfYear":          time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"2024-05-01+1d":        time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+		"2024-05-01T10:00:00Z": time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+	for expr, want := range cases {
+		f, err := query.ParseFilter[stockModel](url.Values{"created_at{gte}": {expr}}, clock)
+		require.NoError(err, expr)
+		require.Len(f, 1, expr)
+		require.True(want.Equal(f[0].Value.(time.Time)), "%s: %s", expr, f[0].Value)
+	}
+
+	for _, expr := range []string{"yesterday", "now-7", "now-7x", "now/q", "now*2"} {
+		_, err := query.ParseFilter[stockModel](url.Values{"created_at{gte}": {expr}}, clock)
+		require.ErrorIs(err, query.ErrInvalidValue, expr)
+	}
+}
+
+func TestParseTimeExprLocation(t *testing.T) {
+	require := require.New(t)
+
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	now := time.Date(2024, 5, 15, 22, 0, 0, 0, time.UTC) // 01:00 on May 16 in loc
+	opts := []query.Option{
+		query.WithClock(func() time.Time { return now }),
+		query.WithLocation(loc),
+	}
+
+	f, err := query.ParseFilter[stockModel](url.Values{
+		"created_at{gte}": {"startOfDay"},
+		"updated_at{lt}":  {"2024-05-01"},
+	}, opts...)
+	require.NoError(err)
+	require.True(time.Date(2024, 5, 16, 0, 0, 0, 0, loc).Equal(f[0].Value.(time.Time)))
+	require.True(time.Date(2024, 5, 1, 0, 0, 0, 0, loc).Equal(f[1].Value.(query.TimeRange).Start))
+}
+
+func TestParseTimeExprLazy(t *testing.T) {
+	require := require.New(t)
+
+	v := url.Values{
+		"created_at{gte}": {"now-7d"},
+		"created_at{lt}":  {"2024-05-20"},
+		"updated_at{in}":  {"startOfDay,startOfDay-1d"},
+	}
+	f, err := query.ParseFilter[stockModel](v, query.WithLazyTime())
+	require.NoError(err)
+	require.Equal(query.Filter{
+		{Field: "created_at", Op: query.OperatorGreaterOrEqual, Value: query.RelativeTime{Expr: "now-7d", Location: time.UTC}},
+		{Field: "created_at", Op: query.OperatorLess, Value: query.TimeRange{
+			Start: time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2024, 5, 21, 0, 0, 0, 0, time.UTC),
+		}},
+		{Field: "updated_at", Op: query.OperatorIn, Value: []query.RelativeTime{
+			{Expr: "startOfDay", Location: time.UTC},
+			{Expr: "startOfDay-1d", Location: time.UTC},
+		}},
+	}, f)
+
+	q := query.Query{Filter: f} //nolint:exhaustruct
+	encoded, err := q.Values()
+	require.NoError(err)
+	require.Equal([]string{"now-7d"}, encoded["created_at{gte}"])
+	require.Equal([]string{"startOfDay,startOfDay-1d"}, encoded["updated_at{in}"])
+
+	data, err := q.MarshalJSON()
+	require.NoError(err)
+	decoded, err := query.Decode[stockModel](data, query.WithLazyTime())
+	require.NoError(err)
+	require.Equal(f, decoded.Filter)
+
+	now := time.Date(2024, 5, 15, 13, 30, 0, 0, time.UTC)
+	resolved, err := q.Resolve(now)
+	require.NoError(err)
+	require.Equal(query.Filter{
+		{Field: "created_at", Op: query.OperatorGreaterOrEqual, Value: now.AddDate(0, 0, -7)},
+		{Field: "created_at", Op: query.OperatorLess, Value: time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)},
+		{Field: "updated_at", Op: query.OperatorIn, Value: []time.Time{
+			time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC),
+		}},
+	}, resolved.Filter)
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	require := require.New(t)
+
+	utc := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	cases := map[string]query.TimeRange{
+		"2024-05-01": {Start: utc(2024, 5, 1), End: utc(2024, 5, 2)},
+		"2024-W18":   {Start: utc(2024, 4, 29), End: utc(2024, 5, 6)},
+		"2021-W01":   {Start: utc(2021, 1, 4), End: utc(2021, 1, 11)},
+		"2024-02":    {Start: utc(2024, 2, 1), End: utc(2024, 3, 1)},
+		"2024":       {Start: utc(2024, 1, 1), End: utc(2025, 1, 1)},
+	}
+	for v, want := range cases {
+		f, err := query.ParseFilter[stockModel](url.Values{"created_at": {v}})
+		require.NoError(err, v)
+		require.Equal(query.Filter{{Field: "created_at", Op: query.OperatorDefault, Value: want}}, f, v)
+
+		s, err := want.QueryMarshal()
+		require.NoError(err)
+		require.Equal(v, s)
+	}
+
+	for _, v := range []string{"2024-W54", "2024-05-01[Mars/Olympus]", "now[UTC]"} {
+		_, err := query.ParseFilter[stockModel](url.Values{"created_at": {v}})
+		require.ErrorIs(err, query.ErrInvalidValue, v)
+	}
+}
+
+func TestParseTimeRangeZone(t *testing.T) {
+	require := require.New(t)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(err)
+	day := query.TimeRange{
+		Start: time.Date(2024, 5, 1, 0, 0, 0, 0, berlin),
+		End:   time.Date(2024, 5, 2, 0, 0, 0, 0, berlin),
+	}
+
+	q, err := query.ParseQuery[stockModel](url.Values{
+		"tz":             {"Europe/Berlin"},
+		"created_at":     {"2024-05-01"},
+		"updated_at{in}": {"2024-05-01[UTC],2024-05-01"},
+	})
+	require.NoError(err)
+	require.Equal(query.Filter{
+		{Field: "created_at", Op: query.OperatorDefault, Value: day},
+		{Field: "updated_at", Op: query.OperatorIn, Value: []query.TimeRange{
+			{Start: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+			day,
+		}},
+	}, q.Filter)
+
+	encoded, err := q.Values()
+	require.NoError(err)
+	require.Equal(url.Values{
+		"created_at":     {"2024-05-01[Europe/Berlin]"},
+		"updated_at{in}": {"2024-05-01,2024-05-01[Europe/Berlin]"},
+	}, encoded)
+	decoded, err := query.ParseQuery[stockModel](encoded)
+	require.NoError(err)
+	require.Equal(q.Filter, decoded.Filter)
+
+	_, err = query.ParseQuery[stockModel](url.Values{"tz": {"Nowhere/City"}, "created_at": {"2024-05-01"}})
+	require.ErrorIs(err, query.ErrInvalidValue)
+}
+
+func TestResolveTimeRange(t *testing.T) {
+	require := require.New(t)
+
+	day := query.TimeRange{
+		Start: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+	}
+	next := query.TimeRange{Start: day.End, End: day.End.AddDate(0, 0, 1)}
+	within := func(r query.TimeRange) query.Filter {
+		return query.Filter{
+			{Field: "created_at", Op: query.OperatorGreaterOrEqual, Value: r.Start},
+			{Field: "created_at", Op: query.OperatorLess, Value: r.End},
+		}
+	}
+
+	f, err := query.Filter{
+		{Field: "created_at", Op: query.OperatorEqual, Value: day},
+		{Field: "created_at", Op: query.OperatorNotEqual, Value: day},
+		{Field: "created_at", Op: query.OperatorGreater, Value: day},
+		{Field: "created_at", Op: query.OperatorLessOrEqual, Value: day},
+		{Field: "created_at", Op: query.OperatorIn, Value: []query.TimeRange{day, next}},
+		{Field: "created_at", Op: query.OperatorBetween, Value: []query.TimeRange{day, next}},
+	}.Resolve(time.Now())
+	require.NoError(err)
+	require.Equal(query.Filter{
+		query.And(within(day)),
+		query.Not(within(day)),
+		{Field: "created_at", Op: query.OperatorGreaterOrEqual, Value: day.End},
+		{Field: "created_at", Op: query.OperatorLess, Value: day.End},
+		query.Or(within(day), within(next)),
+		query.And(within(query.TimeRange{Start: day.Start, End: next.End})),
+	}, f)
+}
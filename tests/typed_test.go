@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/royalcat/query"
+	"github.com/stretchr/testify/require"
+)
+
+var encodeFields = struct {
+	ID        query.OrderedField[encodeModel, id]
+	Tags      query.Field[encodeModel, string]
+	Active    query.Field[encodeModel, bool]
+	CreatedAt query.OrderedField[encodeModel, time.Time]
+}{
+	ID:        query.MustOrderedField[encodeModel, id]("id"),
+	Tags:      query.MustField[encodeModel, string]("tags"),
+	Active:    query.MustField[encodeModel, bool]("active"),
+	CreatedAt: query.MustOrderedField[encodeModel, time.Time]("created_at"),
+}
+
+func TestTypedFields(t *testing.T) {
+	require := require.New(t)
+	ts := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	q := query.New().
+		With(encodeFields.ID.In(1, 2), encodeFields.Active.Eq(true), encodeFields.Tags.Ne("x")).
+		With(encodeFields.CreatedAt.Between(ts, ts.AddDate(0, 1, 0))...).
+		Query()
+	q.Sort = query.Sort{encodeFields.CreatedAt.Desc(), encodeFields.ID.Asc()}
+
+	require.Equal(query.Filter{
+		{Field: "id", Op: query.OperatorIn, Value: []id{1, 2}},
+		{Field: "active", Op: query.OperatorEqual, Value: true},
+		{Field: "tags", Op: query.OperatorNotEqual, Value: "x"},
+		{Field: "created_at", Op: query.OperatorGreaterOrEqual, Value: ts},
+		{Field: "created_at", Op: query.OperatorLessOrEqual, Value: ts.AddDate(0, 1, 0)},
+	}, q.Filter)
+	require.NoError(query.Validate[encodeModel](q))
+
+	_, err := query.NewField[encodeModel, string]("id")
+	require.ErrorIs(err, query.ErrInvalidValue)
+	_, err = query.NewField[encodeModel, string]("missing")
+	require.ErrorIs(err, query.ErrUnknownField)
+	require.Panics(func() { query.MustField[encodeModel, int]("name") })
+}
@@ -0,0 +1,213 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RelativeTime is a time expression kept unresolved by WithLazyTime, so saved
+// queries stay relative. Backends resolve it with the current time, use
+// Query.Resolve to resolve it with another clock.
+//
+// Expressions start with an anchor: "now", "startOfDay", "startOfWeek" (Monday),
+// "startOfMonth", "startOfYear" or a date "2024-05-01", followed by any number of
+// offsets like "-7d" or "+1h" and an optional truncation like "/M".
+// Units are s, m, h, d, w, M and y. Days and larger units are calendar units in Location.
+type RelativeTime struct {
+	Expr     string
+	Location *time.Location
+}
+
+// Resolve evaluates the expression relative to now.
+func (r RelativeTime) Resolve(now time.Time) (time.Time, error) {
+	t, _, err := parseTimeExpr(r.Expr, now, r.Location)
+	return t, err
+}
+
+func (r RelativeTime) QueryMarshal() (string, error) {
+	return r.Expr, nil
+}
+
+var timeAnchors = map[string]func(now time.Time) time.Time{
+	"now":          func(now time.Time) time.Time { return now },
+	"startOfDay":   func(now time.Time) time.Time { return truncateTime(now, 'd') },
+	"startOfWeek":  func(now time.Time) time.Time { return truncateTime(now, 'w') },
+	"startOfMonth": func(now time.Time) time.Time { return truncateTime(now, 'M') },
+	"startOfYear":  func(now time.Time) time.Time { return truncateTime(now, 'y') },
+}
+
+// parseTimeExpr parses RFC3339 timestamps and time expressions, relative is true
+// when the result depends on now.
+func parseTimeExpr(expr string, now time.Time, loc *time.Location) (t time.Time, relative bool, err error) {
+	if t, err := time.Parse(time.RFC3339, expr); err == nil {
+		return t, false, nil
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	rest, dated := expr, false
+	if len(expr) >= len(time.DateOnly) {
+		if d, err := time.ParseInLocation(time.DateOnly, expr[:len(time.DateOnly)], loc); err == nil {
+			t, rest, dated = d, expr[len(time.DateOnly):], true
+		}
+	}
+	if !dated {
+		end := strings.IndexAny(expr, "+-/")
+		if end == -1 {
+			end = len(expr)
+		}
+		anchor, ok := timeAnchors[expr[:end]]
+		if !ok {
+			return time.Time{}, false, fmt.Errorf("cant parse as timestamp or time expression: %s", expr)
+		}
+		t, rest, relative = anchor(now.In(loc)), expr[end:], true
+	}
+
+	for rest != "" {
+		sign := rest[0]
+		rest = rest[1:]
+		if sign == '/' {
+			if len(rest) != 1 || !strings.ContainsRune("smhdwMy", rune(rest[0])) {
+				return time.Time{}, false, fmt.Errorf("invalid truncation in time expression: %s", expr)
+			}
+			return truncateTime(t, rest[0]), relative, nil
+		}
+		if sign != '+' && sign != '-' {
+			return time.Time{}, false, fmt.Errorf("invalid time expression: %s", expr)
+		}
+
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		if i == 0 || i == len(rest) {
+			return time.Time{}, false, fmt.Errorf("invalid offset in time expression: %s", expr)
+		}
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid offset in time expression: %s", expr)
+		}
+		if sign == '-' {
+			n = -n
+		}
+		t, err = addTime(t, n, rest[i])
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w in time expression: %s", err, expr)
+		}
+		rest = rest[i+1:]
+	}
+	return t, relative, nil
+}
+
+func addTime(t time.Time, n int, unit byte) (time.Time, error) {
+	switch unit {
+	case 's':
+		return t.Add(time.Duration(n) * time.Second), nil
+	case 'm':
+		return t.Add(time.Duration(n) * time.Minute), nil
+	case 'h':
+		return t.Add(time.Duration(n) * time.Hour), nil
+	case 'd':
+		return t.AddDate(0, 0, n), nil
+	case 'w':
+		return t.AddDate(0, 0, 7*n), nil
+	case 'M':
+		return t.AddDate(0, n, 0), nil
+	case 'y':
+		return t.AddDate(n, 0, 0), nil
+	}
+	return t, fmt.Errorf("unknown unit %q", unit)
+}
+
+// truncateTime rounds t down to the start of the unit in its location.
+func truncateTime(t time.Time, unit byte) time.Time {
+	y, mo, d := t.Date()
+	h, mi, s := t.Clock()
+	loc := t.Location()
+	switch unit {
+	case 's':
+		return time.Date(y, mo, d, h, mi, s, 0, loc)
+	case 'm':
+		return time.Date(y, mo, d, h, mi, 0, 0, loc)
+	case 'h':
+		return time.Date(y, mo, d, h, 0, 0, 0, loc)
+	case 'd':
+		return time.Date(y, mo, d, 0, 0, 0, 0, loc)
+	case 'w':
+		return time.Date(y, mo, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, loc)
+	case 'M':
+		return time.Date(y, mo, 1, 0, 0, 0, 0, loc)
+	case 'y':
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, loc)
+	}
+	return t
+}
+
+// Resolve returns the filter with plain time values backends can compare against:
+// RelativeTime values are resolved relative to now and TimeRange values are
+// expanded to conditions on their bounds.
+func (q Filter) Resolve(now time.Time) (Filter, error) {
+	out := make(Filter, 0, len(q))
+	for _, f := range q {
+		if group, ok := f.Group(); ok {
+			groups := make([]Filter, 0, len(group))
+			for _, g := range group {
+				rg, err := g.Resolve(now)
+				if err != nil {
+					return nil, err
+				}
+				groups = append(groups, rg)
+			}
+			out = append(out, FieldFilter{Field: f.Field, Op: f.Op, Value: groups})
+			continue
+		}
+		if elem, ok := f.Elem(); ok {
+			re, err := elem.Resolve(now)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, ElemMatch(f.Field, re))
+			continue
+		}
+
+		switch v := f.Value.(type) {
+		case TimeRange, []TimeRange:
+			var err error
+			f, err = rangeBounds(f)
+			if err != nil {
+				return nil, &FieldError{Err: ErrInvalidValue, Field: f.Field, Op: f.Op, Value: "", Cause: err}
+			}
+		case RelativeTime:
+			t, err := v.Resolve(now)
+			if err != nil {
+				return nil, &FieldError{Err: ErrInvalidValue, Field: f.Field, Op: f.Op, Value: v.Expr, Cause: err}
+			}
+			f.Value = t
+		case []RelativeTime:
+			times := make([]time.Time, 0, len(v))
+			for _, r := range v {
+				t, err := r.Resolve(now)
+				if err != nil {
+					return nil, &FieldError{Err: ErrInvalidValue, Field: f.Field, Op: f.Op, Value: r.Expr, Cause: err}
+				}
+				times = append(times, t)
+			}
+			f.Value = times
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// Resolve is Filter.Resolve for the query filter.
+func (q Query) Resolve(now time.Time) (Query, error) {
+	f, err := q.Filter.Resolve(now)
+	if err != nil {
+		return Query{}, err
+	}
+	q.Filter = f
+	return q, nil
+}
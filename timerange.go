@@ -0,0 +1,176 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeRange is the half-open calendar period [Start, End) of a date-only value:
+// a day "2024-05-01", an ISO week "2024-W18", a month "2024-05" or a year "2024".
+// Dates are in the parser location (WithLocation or the TimeZone parameter) unless
+// they have a zone suffix like "2024-05-01[Europe/Berlin]".
+//
+// Operators compare against the whole period: eq matches instants inside it,
+// gt instants after it, lte instants before its end and between spans from the
+// start of the first period to the end of the second. Filter.Resolve expands
+// ranges to plain time bounds.
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// QueryMarshal formats the range as the date-only value it was parsed from,
+// with a zone suffix when it isn't in UTC.
+func (r TimeRange) QueryMarshal() (string, error) {
+	var s string
+	switch start := r.Start; {
+	case r.End.Equal(start.AddDate(0, 0, 1)):
+		s = start.Format(time.DateOnly)
+	case r.End.Equal(start.AddDate(0, 0, 7)) && start.Weekday() == time.Monday:
+		y, w := start.ISOWeek()
+		s = fmt.Sprintf("%04d-W%02d", y, w)
+	case r.End.Equal(start.AddDate(0, 1, 0)):
+		s = start.Format("2006-01")
+	case r.End.Equal(start.AddDate(1, 0, 0)):
+		s = start.Format("2006")
+	default:
+		return "", fmt.Errorf("time range %s - %s is not a calendar period", r.Start, r.End)
+	}
+	if loc := r.Start.Location(); loc != time.UTC {
+		s += "[" + loc.String() + "]"
+	}
+	return s, nil
+}
+
+// parseTimeRange parses a date-only value, ok is false when v is not one.
+func parseTimeRange(v string, loc *time.Location) (r TimeRange, ok bool, err error) {
+	if i := strings.IndexByte(v, '['); i != -1 && strings.HasSuffix(v, "]") {
+		loc, err = time.LoadLocation(v[i+1 : len(v)-1])
+		if err != nil {
+			return TimeRange{}, false, err
+		}
+		r, ok, err = parseTimeRange(v[:i], loc)
+		if err == nil && !ok {
+			err = fmt.Errorf("zone suffix on a value that is not a date: %s", v)
+		}
+		return r, ok, err
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	switch {
+	case len(v) == len(time.DateOnly):
+		start, err := time.ParseInLocation(time.DateOnly, v, loc)
+		if err != nil {
+			return TimeRange{}, false, nil
+		}
+		return TimeRange{Start: start, End: start.AddDate(0, 0, 1)}, true, nil
+	case len(v) == len("2006-W01") && v[4:6] == "-W":
+		year, err1 := strconv.Atoi(v[:4])
+		week, err2 := strconv.Atoi(v[6:])
+		if err1 != nil || err2 != nil {
+			return TimeRange{}, false, nil
+		}
+		// January 4th is always in the first ISO week
+		jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+		start := jan4.AddDate(0, 0, 7*(week-1)-(int(jan4.Weekday())+6)%7)
+		if y, w := start.ISOWeek(); y != year || w != week {
+			return TimeRange{}, false, fmt.Errorf("week %d out of range for %d", week, year)
+		}
+		return TimeRange{Start: start, End: start.AddDate(0, 0, 7)}, true, nil
+	case len(v) == len("2006-01"):
+		start, err := time.ParseInLocation("2006-01", v, loc)
+		if err != nil {
+			return TimeRange{}, false, nil
+		}
+		return TimeRange{Start: start, End: start.AddDate(0, 1, 0)}, true, nil
+	case len(v) == len("2006"):
+		start, err := time.ParseInLocation("2006", v, loc)
+		if err != nil {
+			return TimeRange{}, false, nil
+		}
+		return TimeRange{Start: start, End: start.AddDate(1, 0, 0)}, true, nil
+	}
+	return TimeRange{}, false, nil
+}
+
+func isRangeOperator(op Operator) bool {
+	switch op {
+	case OperatorDefault, OperatorEqual, OperatorNotEqual,
+		OperatorGreater, OperatorGreaterOrEqual, OperatorLess, OperatorLessOrEqual,
+		OperatorIn, OperatorNotIn, OperatorBetween:
+		return true
+	}
+	return false
+}
+
+// timeRanges parses values as TimeRange when all of them are date-only.
+func (p *parser) timeRanges(op Operator, values []string) (val any, ok bool, err error) {
+	if !isRangeOperator(op) {
+		return nil, false, nil
+	}
+	ranges := make([]TimeRange, 0, len(values))
+	for _, v := range values {
+		r, ok, err := parseTimeRange(v, p.opts.location)
+		if !ok || err != nil {
+			return nil, false, err
+		}
+		ranges = append(ranges, r)
+	}
+	if isListOperator(op) {
+		return ranges, true, nil
+	}
+	if len(ranges) != 1 {
+		return nil, false, fmt.Errorf("expected one value, got %d", len(ranges))
+	}
+	return ranges[0], true, nil
+}
+
+// rangeBounds expands a TimeRange condition to conditions on its bounds.
+func rangeBounds(f FieldFilter) (FieldFilter, error) {
+	within := func(r TimeRange) Filter {
+		return Filter{
+			{Field: f.Field, Op: OperatorGreaterOrEqual, Value: r.Start},
+			{Field: f.Field, Op: OperatorLess, Value: r.End},
+		}
+	}
+
+	if r, ok := f.Value.(TimeRange); ok {
+		switch f.Op {
+		case OperatorDefault, OperatorEqual:
+			return And(within(r)), nil
+		case OperatorNotEqual:
+			return Not(within(r)), nil
+		case OperatorGreater:
+			return FieldFilter{Field: f.Field, Op: OperatorGreaterOrEqual, Value: r.End}, nil
+		case OperatorGreaterOrEqual:
+			return FieldFilter{Field: f.Field, Op: OperatorGreaterOrEqual, Value: r.Start}, nil
+		case OperatorLess:
+			return FieldFilter{Field: f.Field, Op: OperatorLess, Value: r.Start}, nil
+		case OperatorLessOrEqual:
+			return FieldFilter{Field: f.Field, Op: OperatorLess, Value: r.End}, nil
+		}
+		return f, fmt.Errorf("operator %s doesn't support time ranges", f.Op)
+	}
+
+	ranges := f.Value.([]TimeRange)
+	groups := make([]Filter, 0, len(ranges))
+	for _, r := range ranges {
+		groups = append(groups, within(r))
+	}
+	switch f.Op {
+	case OperatorIn:
+		return Or(groups...), nil
+	case OperatorNotIn:
+		return Not(groups...), nil
+	case OperatorBetween:
+		if len(ranges) != 2 {
+			return f, fmt.Errorf("between expects 2 values, got %d", len(ranges))
+		}
+		return And(within(TimeRange{Start: ranges[0].Start, End: ranges[1].End})), nil
+	}
+	return f, fmt.Errorf("operator %s doesn't support time ranges", f.Op)
+}
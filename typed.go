@@ -0,0 +1,111 @@
+package query
+
+import (
+	"fmt"
+	"time"
+)
+
+// Field is a typed reference to a field of Model holding values of type T
+// (element type for slice fields). Build descriptors once per model:
+//
+//	var userFields = struct {
+//		Name query.Field[User, string]
+//		Age  query.OrderedField[User, int]
+//	}{
+//		Name: query.MustField[User, string]("name"),
+//		Age:  query.MustOrderedField[User, int]("age"),
+//	}
+//
+//	f := query.Filter{userFields.Name.In("a", "b"), userFields.Age.Gt(18)}
+type Field[Model any, T any] struct {
+	path string
+}
+
+// NewField checks that path exists in Model and holds values of type T.
+func NewField[Model any, T any](path string) (Field[Model, T], error) {
+	t, err := GetTypeByPath(modelType[Model](), path)
+	if err != nil {
+		return Field[Model, T]{}, err //nolint:exhaustruct
+	}
+
+	want := genericType[T]()
+	if t != want && elemType(t) != want {
+		return Field[Model, T]{}, &FieldError{ //nolint:exhaustruct
+			Err: ErrInvalidValue, Field: path, Op: OperatorDefault, Value: "",
+			Cause: fmt.Errorf("field type is %s, not %s", t.String(), want.String()),
+		}
+	}
+	return Field[Model, T]{path: path}, nil
+}
+
+// MustField is NewField that panics on error, for descriptors declared at package level.
+func MustField[Model any, T any](path string) Field[Model, T] {
+	f, err := NewField[Model, T](path)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func (f Field[Model, T]) Path() string {
+	return f.path
+}
+
+func (f Field[Model, T]) filter(op Operator, v any) FieldFilter {
+	return FieldFilter{Field: f.path, Op: op, Value: v}
+}
+
+func (f Field[Model, T]) Eq(v T) FieldFilter { return f.filter(OperatorEqual, v) }
+func (f Field[Model, T]) Ne(v T) FieldFilter { return f.filter(OperatorNotEqual, v) }
+
+func (f Field[Model, T]) In(vs ...T) FieldFilter {
+	if vs == nil {
+		vs = []T{}
+	}
+	return f.filter(OperatorIn, vs)
+}
+
+func (f Field[Model, T]) NotIn(vs ...T) FieldFilter {
+	if vs == nil {
+		vs = []T{}
+	}
+	return f.filter(OperatorNotIn, vs)
+}
+
+func (f Field[Model, T]) Asc() SortField {
+	return SortField{Key: f.path, Order: ASC, Nulls: NullsDefault}
+}
+func (f Field[Model, T]) Desc() SortField {
+	return SortField{Key: f.path, Order: DESC, Nulls: NullsDefault}
+}
+
+// Ordered are types supporting range comparisons.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64 | ~string | time.Time
+}
+
+// OrderedField is a Field with range comparisons.
+type OrderedField[Model any, T Ordered] struct {
+	Field[Model, T]
+}
+
+func NewOrderedField[Model any, T Ordered](path string) (OrderedField[Model, T], error) {
+	f, err := NewField[Model, T](path)
+	return OrderedField[Model, T]{Field: f}, err
+}
+
+func MustOrderedField[Model any, T Ordered](path string) OrderedField[Model, T] {
+	return OrderedField[Model, T]{Field: MustField[Model, T](path)}
+}
+
+func (f OrderedField[Model, T]) Gt(v T) FieldFilter  { return f.filter(OperatorGreater, v) }
+func (f OrderedField[Model, T]) Gte(v T) FieldFilter { return f.filter(OperatorGreaterOrEqual, v) }
+func (f OrderedField[Model, T]) Lt(v T) FieldFilter  { return f.filter(OperatorLess, v) }
+func (f OrderedField[Model, T]) Lte(v T) FieldFilter { return f.filter(OperatorLessOrEqual, v) }
+
+// Between matches values in the inclusive range.
+func (f OrderedField[Model, T]) Between(from, to T) Filter {
+	return Filter{f.Gte(from), f.Lte(to)}
+}
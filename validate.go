@@ -0,0 +1,130 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Validate checks a query built in code against the model the same way parsing does:
+// fields must exist, operators must suit the field type and, with WithPolicy, be allowed.
+// With WithLimits the query must be within the limits.
+func Validate[Model any](q Query, opts ...Option) error {
+	p := newParser[Model](opts)
+	p.validate(q)
+	return p.errs.err()
+}
+
+func (p *parser) validate(q Query) bool {
+	if p.errs.add(p.opts.limits.Check(q)) {
+		return false
+	}
+	if !p.validateFilter(q.Filter) {
+		return false
+	}
+	for _, s := range q.Sort {
+		if p.errs.add(p.sortField(s.Key)) {
+			return false
+		}
+	}
+	for _, f := range q.Select {
+		if _, err := GetTypeByPath(p.t, f); p.errs.add(err) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) validateFilter(f Filter) bool {
+	for _, filter := range f {
+		if group, ok := filter.Group(); ok {
+			for _, g := range group {
+				if !p.validateFilter(g) {
+					return false
+				}
+			}
+			continue
+		}
+		if elem, ok := filter.Elem(); ok {
+			if !p.validateElem(filter.Field, elem) {
+				return false
+			}
+			continue
+		}
+
+		if err := p.checkValue(filter); err != nil {
+			if p.errs.add(err) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checkValue checks a filter with a typed value, including field references.
+func (p *parser) checkValue(f FieldFilter) error {
+	if ref, ok := f.Value.(FieldRef); ok {
+		if !isRefOperator(f.Op) {
+			return &FieldError{Err: ErrInvalidValue, Field: f.Field, Op: f.Op, Value: "$" + string(ref), Cause: fmt.Errorf("field references are not supported by %s", f.Op)}
+		}
+		_, err := p.fieldRef(f.Field, f.Op, ref)
+		return err
+	}
+	_, err := p.fieldType(f.Field, f.Op)
+	return err
+}
+
+// validateElem checks that field is a slice and elem is valid for its elements,
+// policy applies to the full paths of the element fields.
+func (p *parser) validateElem(field string, elem Filter) bool {
+	if _, err := p.sliceType(field); err != nil {
+		return !p.errs.add(err)
+	}
+	if err := checkElemConditions(field, elem); err != nil {
+		return !p.errs.add(err)
+	}
+	return p.validateFilter(elem.mapFields(func(path string) string {
+		return elemPath(field, path)
+	}))
+}
+
+// checkElemConditions rejects an element filter mixing conditions on the element itself and on its fields.
+func checkElemConditions(field string, elem Filter) error {
+	if self, fields := elemConditions(elem); self && fields {
+		return &FieldError{
+			Err: ErrInvalidValue, Field: field, Op: OperatorElemMatch, Value: "",
+			Cause: fmt.Errorf("conditions on the element itself can't be mixed with conditions on its fields"),
+		}
+	}
+	return nil
+}
+
+// elemConditions reports whether an element filter has conditions on the element
+// itself (empty field) and on its fields, nested element filters are not inspected.
+func elemConditions(elem Filter) (self, fields bool) {
+	for _, f := range elem {
+		if group, ok := f.Group(); ok {
+			for _, g := range group {
+				s, fs := elemConditions(g)
+				self, fields = self || s, fields || fs
+			}
+			continue
+		}
+		if f.Field == "" {
+			self = true
+		} else {
+			fields = true
+		}
+	}
+	return self, fields
+}
+
+func (p *parser) sliceType(field string) (reflect.Type, error) {
+	t, err := GetTypeByPath(p.t, field)
+	if err != nil {
+		return nil, err
+	}
+	if !operatorAllowed(OperatorElemMatch, t) {
+		return nil, operatorNotAllowed(field, OperatorElemMatch, t.String())
+	}
+	return t, nil
+}